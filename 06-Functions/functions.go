@@ -8,14 +8,21 @@ This file demonstrates:
 - Functions as parameters (higher-order functions)
 - Working with the math package
 - Formatted output with Printf
+- Using a package from another lesson (the greeting engine of 08-PackageScope)
 */
 package main
 
 import (
+	"08-PackageScope/greeting"
 	"fmt"
 	"math"
+	"time"
 )
 
+// greeter picks "Good morning/afternoon/evening" from the local time
+// It lives in 08-PackageScope/greeting, so these lessons greet the same way
+var greeter = greeting.NewGreeter(greeting.SystemClock{}, time.Local, "en")
+
 func main() {
 	basicFunctions()
 	functionWithReturn()
@@ -38,13 +45,14 @@ func basicFunctions() {
 // sayGreetings is a simple function that takes a string parameter
 // - "name string" declares a parameter named "name" of type string
 // - No return type specified (void function)
+// - The greeting depends on the time of day ("Good evening" after 18:00)
 func sayGreetings(name string) {
-	fmt.Printf("Good morning %v\n", name)
+	fmt.Println(greeter.Greet(name))
 }
 
 // sayHaveNiceDay shows another simple function example
 func sayHaveNiceDay(name string) {
-	fmt.Printf("Have a nice day %v\n", name)
+	fmt.Println(greeter.NiceDay(name))
 }
 
 // ============================================
@@ -0,0 +1,7 @@
+module 06-Functions
+
+go 1.25.5
+
+require 08-PackageScope v0.0.0
+
+replace 08-PackageScope => ../08-PackageScope
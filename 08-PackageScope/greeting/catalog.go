@@ -0,0 +1,82 @@
+package greeting
+
+// =====================
+// MESSAGE CATALOGS
+// =====================
+
+// Message keys used by the Greeter
+// Templates use text/template syntax: {{.Name}} is replaced by the person's name
+const (
+	KeyHello     = "hello"
+	KeyMorning   = "morning"
+	KeyAfternoon = "afternoon"
+	KeyEvening   = "evening"
+	KeyNiceDay   = "nice_day"
+	KeyBirthday  = "birthday"
+)
+
+// Catalog holds every message for one language
+type Catalog struct {
+	Language    string            // Language code (e.g., "en", "ar")
+	RightToLeft bool              // True for scripts written right to left (Arabic, Hebrew...)
+	Messages    map[string]string // Message key -> template
+}
+
+// DefaultCatalogs returns the built-in languages
+// Each call returns fresh maps, so adding templates never changes the defaults
+func DefaultCatalogs() map[string]Catalog {
+	return map[string]Catalog{
+		"en": {
+			Language: "en",
+			Messages: map[string]string{
+				KeyHello:            "Hello, {{.Name}}",
+				KeyMorning:          "Good morning, {{.Name}}",
+				KeyAfternoon:        "Good afternoon, {{.Name}}",
+				KeyEvening:          "Good evening, {{.Name}}",
+				KeyNiceDay:          "Have a nice day, {{.Name}}!",
+				KeyBirthday:         "Happy birthday, {{.Name}}!",
+				"holiday:new_year":  "Happy New Year, {{.Name}}!",
+				"holiday:christmas": "Merry Christmas, {{.Name}}!",
+			},
+		},
+		"nl": {
+			Language: "nl",
+			Messages: map[string]string{
+				KeyHello:            "Hallo, {{.Name}}",
+				KeyMorning:          "Goedemorgen, {{.Name}}",
+				KeyAfternoon:        "Goedemiddag, {{.Name}}",
+				KeyEvening:          "Goedenavond, {{.Name}}",
+				KeyNiceDay:          "Fijne dag, {{.Name}}!",
+				KeyBirthday:         "Gefeliciteerd met je verjaardag, {{.Name}}!",
+				"holiday:new_year":  "Gelukkig nieuwjaar, {{.Name}}!",
+				"holiday:christmas": "Vrolijk kerstfeest, {{.Name}}!",
+			},
+		},
+		"fr": {
+			Language: "fr",
+			Messages: map[string]string{
+				KeyHello:            "Salut, {{.Name}}",
+				KeyMorning:          "Bonjour, {{.Name}}",
+				KeyAfternoon:        "Bon après-midi, {{.Name}}",
+				KeyEvening:          "Bonsoir, {{.Name}}",
+				KeyNiceDay:          "Bonne journée, {{.Name}} !",
+				KeyBirthday:         "Joyeux anniversaire, {{.Name}} !",
+				"holiday:new_year":  "Bonne année, {{.Name}} !",
+				"holiday:christmas": "Joyeux Noël, {{.Name}} !",
+			},
+		},
+		"ar": {
+			Language:    "ar",
+			RightToLeft: true,
+			Messages: map[string]string{
+				KeyHello:           "مرحبا، {{.Name}}",
+				KeyMorning:         "صباح الخير، {{.Name}}",
+				KeyAfternoon:       "مساء الخير، {{.Name}}",
+				KeyEvening:         "مساء الخير، {{.Name}}",
+				KeyNiceDay:         "أتمنى لك يوما سعيدا، {{.Name}}!",
+				KeyBirthday:        "عيد ميلاد سعيد، {{.Name}}!",
+				"holiday:new_year": "سنة سعيدة، {{.Name}}!",
+			},
+		},
+	}
+}
@@ -0,0 +1,213 @@
+package greeting
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// =====================
+// CLOCK
+// =====================
+
+// Clock tells the Greeter what time it is
+// Passing a different Clock lets us test "Good evening" at 10 in the morning
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock uses the real time
+type SystemClock struct{}
+
+// Now returns time.Now()
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock always returns the same moment
+type FixedClock time.Time
+
+// Now returns the fixed moment
+func (clock FixedClock) Now() time.Time {
+	return time.Time(clock)
+}
+
+// =====================
+// GREETER
+// =====================
+
+// holiday is a day of the year with its own greeting
+type holiday struct {
+	month time.Month
+	day   int
+	key   string // Message key, e.g., "holiday:christmas"
+}
+
+// Greeter builds greetings in one language, for one time zone
+type Greeter struct {
+	clock    Clock
+	location *time.Location
+	language string
+	catalogs map[string]Catalog
+	holidays []holiday
+}
+
+// Unicode bidirectional isolates: they keep a Latin name inside an Arabic
+// sentence (or the other way around) from scrambling the word order
+const (
+	rightToLeftIsolate = "\u2067"
+	firstStrongIsolate = "\u2068"
+	popIsolate         = "\u2069"
+)
+
+// NewGreeter creates a Greeter with the built-in catalogs and holidays
+// A nil location means UTC; unknown languages fall back to English
+func NewGreeter(clock Clock, location *time.Location, language string) *Greeter {
+	if location == nil {
+		location = time.UTC
+	}
+	greeter := &Greeter{
+		clock:    clock,
+		location: location,
+		language: language,
+		catalogs: DefaultCatalogs(),
+	}
+	greeter.AddHoliday(time.January, 1, "new_year")
+	greeter.AddHoliday(time.December, 25, "christmas")
+	return greeter
+}
+
+// =====================
+// RECEIVER FUNCTIONS (METHODS)
+// =====================
+
+// AddCatalog registers (or replaces) a language
+func (greeter *Greeter) AddCatalog(catalog Catalog) {
+	if catalog.Messages == nil {
+		catalog.Messages = map[string]string{} // So AddTemplate can fill it later
+	}
+	greeter.catalogs[catalog.Language] = catalog
+}
+
+// AddTemplate adds or replaces one message in a language
+// e.g., AddTemplate("en", "holiday:kings_day", "Happy King's Day, {{.Name}}!")
+func (greeter *Greeter) AddTemplate(language string, key string, text string) error {
+	if _, err := template.New(key).Parse(text); err != nil {
+		return err
+	}
+	catalog, ok := greeter.catalogs[language]
+	if !ok {
+		catalog = Catalog{Language: language}
+	}
+	if catalog.Messages == nil {
+		catalog.Messages = map[string]string{}
+	}
+	catalog.Messages[key] = text
+	greeter.catalogs[language] = catalog
+	return nil
+}
+
+// AddHoliday makes Greet use the "holiday:<name>" message on that day every year
+func (greeter *Greeter) AddHoliday(month time.Month, day int, name string) {
+	greeter.holidays = append(greeter.holidays, holiday{month: month, day: day, key: "holiday:" + name})
+}
+
+// Period returns "morning", "afternoon" or "evening" for the current time
+// in the Greeter's time zone (night counts as evening)
+func (greeter *Greeter) Period() string {
+	hour := greeter.clock.Now().In(greeter.location).Hour()
+	switch {
+	case hour >= 5 && hour < 12:
+		return KeyMorning
+	case hour >= 12 && hour < 18:
+		return KeyAfternoon
+	default:
+		return KeyEvening
+	}
+}
+
+// Hello returns a plain "Hello, name"
+func (greeter *Greeter) Hello(name string) string {
+	return greeter.Message(KeyHello, name)
+}
+
+// Greet returns the holiday greeting on a holiday,
+// otherwise "Good morning/afternoon/evening, name"
+func (greeter *Greeter) Greet(name string) string {
+	now := greeter.clock.Now().In(greeter.location)
+	for _, day := range greeter.holidays {
+		if now.Month() == day.month && now.Day() == day.day && greeter.has(day.key) {
+			return greeter.Message(day.key, name)
+		}
+	}
+	return greeter.Message(greeter.Period(), name)
+}
+
+// NiceDay returns "Have a nice day, name!"
+func (greeter *Greeter) NiceDay(name string) string {
+	return greeter.Message(KeyNiceDay, name)
+}
+
+// Birthday returns the birthday greeting
+func (greeter *Greeter) Birthday(name string) string {
+	return greeter.Message(KeyBirthday, name)
+}
+
+// Message renders any message key for a name
+// It falls back to English when the language has no such message
+func (greeter *Greeter) Message(key string, name string) string {
+	catalog := greeter.catalog(key)
+	text, ok := catalog.Messages[key]
+	if !ok {
+		return fmt.Sprintf("[missing message %q] %s", key, name)
+	}
+
+	// In right-to-left text the name is isolated so its own direction is kept
+	data := struct{ Name string }{Name: name}
+	if catalog.RightToLeft {
+		data.Name = firstStrongIsolate + name + popIsolate
+	}
+
+	var builder strings.Builder
+	tmpl, err := template.New(key).Parse(text)
+	if err == nil {
+		err = tmpl.Execute(&builder, data)
+	}
+	if err != nil {
+		return fmt.Sprintf("[bad message %q: %v] %s", key, err, name)
+	}
+
+	if catalog.RightToLeft {
+		return rightToLeftIsolate + builder.String() + popIsolate
+	}
+	return builder.String()
+}
+
+// =====================
+// PRIVATE METHODS
+// =====================
+
+// catalog picks the Greeter's language, or English if the key is missing there
+func (greeter *Greeter) catalog(key string) Catalog {
+	if catalog, ok := greeter.catalogs[greeter.language]; ok {
+		if _, found := catalog.Messages[key]; found {
+			return catalog
+		}
+	}
+	return greeter.catalogs["en"]
+}
+
+// has reports whether the key exists in the Greeter's language or in English
+func (greeter *Greeter) has(key string) bool {
+	_, ok := greeter.catalog(key).Messages[key]
+	return ok
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// greeting.NewGreeter(greeting.SystemClock{}, time.Local, "en") -> real clock
+// greeting.FixedClock(someTime)                                 -> fake clock for examples/tests
+// greeter.Greet(name)      -> morning/afternoon/evening (or holiday) greeting
+// greeter.AddTemplate(...) -> add birthday/holiday messages per language
@@ -1,20 +1,129 @@
 package main
 
 import (
+	"08-PackageScope/greeting"
 	"08-PackageScope/package1"
 	"08-PackageScope/package2"
+	"08-PackageScope/stats"
+	"flag"
 	"fmt"
+	"os"
+	"time"
 )
 
 func main() {
+	sourceSpec := flag.String("source", "", "where to load numbers from (list, list:1,2, file:x.txt, csv:x.csv#col, stdin, env:NAME)")
+	statName := flag.String("stat", "all", "statistic to show (mean, median, mode, variance, stddev, min, max, p<N>, all)")
+	language := flag.String("lang", "en", "greeting language (en, nl, fr, ar)")
+	timeZone := flag.String("tz", "Local", "time zone for the greeting (e.g., Europe/Amsterdam)")
+	at := flag.String("at", "", "pretend it is this time (RFC 3339, e.g., 2026-12-25T09:00:00Z)")
+	flag.Parse()
+
+	if err := setupGreeter(*language, *timeZone, *at); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
 	package1.SayHello("Mahmoud")
-	AvgInIntSlice(package2.Numbers)
+	package1.SayGreetings("Mahmoud")
+	defer package1.SayHaveNiceDay("Mahmoud")
+
+	// Without --source we keep the original example
+	if *sourceSpec == "" {
+		AvgInIntSlice(package2.Numbers)
+		return
+	}
+
+	source, err := package2.ParseSource(*sourceSpec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	numbers, err := source.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+
+	if err := printStat(*statName, numbers); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
 }
 
+// setupGreeter replaces package1.Greeter with one for the chosen language, zone and time
+func setupGreeter(language string, timeZone string, at string) error {
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return err
+	}
+
+	var clock greeting.Clock = greeting.SystemClock{}
+	if at != "" {
+		moment, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return err
+		}
+		clock = greeting.FixedClock(moment)
+	}
+
+	package1.Greeter = greeting.NewGreeter(clock, location, language)
+	return nil
+}
+
+// AvgInIntSlice prints the average of the slice
+// An empty slice prints an error instead of panicking with a divide-by-zero
 func AvgInIntSlice(slice []int) {
-	avgNum := 0
-	for _, value := range slice {
-		avgNum += value
+	avgNum, err := stats.Mean(slice)
+	if err != nil {
+		fmt.Println("avg: ", err)
+		return
 	}
-	fmt.Println("avg: ", (avgNum / len(slice)))
+	fmt.Println("avg: ", avgNum)
+}
+
+// printStat prints one statistic (or all of them) for the numbers
+func printStat(name string, numbers []float64) error {
+	if name == "all" {
+		for _, each := range []string{"count", "mean", "median", "mode", "variance", "stddev", "min", "max", "p90"} {
+			if err := printStat(each, numbers); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var value any
+	var err error
+	switch name {
+	case "count":
+		value = len(numbers)
+	case "mean":
+		value, err = stats.Mean(numbers)
+	case "median":
+		value, err = stats.Median(numbers)
+	case "mode":
+		value, err = stats.Mode(numbers)
+	case "variance":
+		value, err = stats.Variance(numbers)
+	case "stddev":
+		value, err = stats.StdDev(numbers)
+	case "min":
+		value, _, err = stats.MinMax(numbers)
+	case "max":
+		_, value, err = stats.MinMax(numbers)
+	default:
+		// p<N> -> percentile, e.g., p90 or p99.9
+		var rank float64
+		if _, scanErr := fmt.Sscanf(name, "p%g", &rank); scanErr != nil {
+			return fmt.Errorf("unknown statistic %q", name)
+		}
+		value, err = stats.Percentile(numbers, rank)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %v\n", name, value)
+	return nil
 }
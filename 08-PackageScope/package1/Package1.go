@@ -1,9 +1,25 @@
 package package1
 
 import (
+	"08-PackageScope/greeting"
 	"fmt"
+	"time"
 )
 
+// Greeter is used by every Say function
+// Replace it to change language, time zone or clock (see main.go)
+var Greeter = greeting.NewGreeter(greeting.SystemClock{}, time.Local, "en")
+
 func SayHello(name string) {
-	fmt.Println("Hello, ", name)
+	fmt.Println(Greeter.Hello(name))
+}
+
+// SayGreetings prints "Good morning/afternoon/evening" for the current time
+func SayGreetings(name string) {
+	fmt.Println(Greeter.Greet(name))
+}
+
+// SayHaveNiceDay prints a goodbye wish
+func SayHaveNiceDay(name string) {
+	fmt.Println(Greeter.NiceDay(name))
 }
@@ -0,0 +1,231 @@
+package package2
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// =====================
+// NUMBER SOURCE INTERFACE
+// =====================
+
+// NumberSource is anything that can load a list of numbers
+// Numbers (above) is only one possible source: files, stdin, env vars... also work
+type NumberSource interface {
+	Load() ([]float64, error)
+}
+
+// =====================
+// LITERAL LIST
+// =====================
+
+// ListSource is a fixed list of numbers written in code
+type ListSource []float64
+
+// Load returns the list itself
+func (source ListSource) Load() ([]float64, error) {
+	return source, nil
+}
+
+// IntListSource turns an []int (like Numbers) into a ListSource
+func IntListSource(numbers []int) ListSource {
+	source := make(ListSource, len(numbers))
+	for i, number := range numbers {
+		source[i] = float64(number)
+	}
+	return source
+}
+
+// =====================
+// TEXT FILE / STDIN / ENV
+// =====================
+
+// FileSource reads a text file with one number per line or comma-separated numbers
+type FileSource struct {
+	Path string
+}
+
+// Load reads and parses the whole file
+func (source FileSource) Load() ([]float64, error) {
+	data, err := os.ReadFile(source.Path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNumbers(string(data))
+}
+
+// ReaderSource reads numbers from any io.Reader (e.g., os.Stdin)
+type ReaderSource struct {
+	Reader io.Reader
+}
+
+// Load reads everything until EOF and parses it
+func (source ReaderSource) Load() ([]float64, error) {
+	data, err := io.ReadAll(source.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNumbers(string(data))
+}
+
+// EnvSource reads comma-separated numbers from an environment variable
+type EnvSource struct {
+	Name string
+}
+
+// Load parses the variable, which must be set
+func (source EnvSource) Load() ([]float64, error) {
+	value, ok := os.LookupEnv(source.Name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", source.Name)
+	}
+	return ParseNumbers(value)
+}
+
+// ParseNumbers splits text on newlines and commas and parses each number
+// Empty entries and lines starting with # are skipped
+func ParseNumbers(text string) ([]float64, error) {
+	numbers := []float64{}
+	for lineNumber, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, field := range strings.Split(line, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			number, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q is not a number", lineNumber+1, field)
+			}
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers, nil
+}
+
+// =====================
+// CSV COLUMN
+// =====================
+
+// CSVSource reads one column of a CSV file
+// Column is either a header name (e.g., "age") or a 0-based index (e.g., "2")
+type CSVSource struct {
+	Path   string
+	Column string
+}
+
+// Load reads the CSV and parses every value in the chosen column
+func (source CSVSource) Load() ([]float64, error) {
+	file, err := os.Open(source.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	// Keep the file line of every row: quoted fields can span lines,
+	// so the row number alone would point at the wrong line
+	reader := csv.NewReader(file)
+	records, lines := [][]string{}, []int{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	if len(records) == 0 {
+		return []float64{}, nil
+	}
+
+	// A header name is looked up in the first row, which is then skipped
+	// With an index, the first row is skipped only when it is not a number (a header)
+	column, err := strconv.Atoi(source.Column)
+	if err != nil {
+		column = -1
+		for i, name := range records[0] {
+			if strings.EqualFold(strings.TrimSpace(name), source.Column) {
+				column = i
+				break // The first column with the name wins
+			}
+		}
+		if column < 0 {
+			return nil, fmt.Errorf("%s: no column named %q", source.Path, source.Column)
+		}
+		records, lines = records[1:], lines[1:]
+	} else if column < 0 {
+		return nil, fmt.Errorf("%s: column index %d is negative (columns count from 0)", source.Path, column)
+	} else if column < len(records[0]) {
+		field := strings.TrimSpace(records[0][column])
+		if _, err := strconv.ParseFloat(field, 64); err != nil && field != "" {
+			records, lines = records[1:], lines[1:]
+		}
+	}
+
+	numbers := []float64{}
+	for i, record := range records {
+		if column >= len(record) {
+			return nil, fmt.Errorf("%s: line %d has no column %d", source.Path, lines[i], column)
+		}
+		field := strings.TrimSpace(record[column])
+		if field == "" {
+			continue
+		}
+		number, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: %q is not a number", source.Path, lines[i], field)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
+
+// =====================
+// CHOOSING A SOURCE
+// =====================
+
+// ParseSource turns a --source flag value into a NumberSource
+//
+//	list             -> the Numbers variable
+//	list:1,2,3       -> a literal list
+//	file:data.txt    -> a text file
+//	csv:data.csv#age -> the "age" column of a CSV file
+//	stdin            -> standard input
+//	env:NUMBERS      -> an environment variable
+func ParseSource(spec string) (NumberSource, error) {
+	kind, argument, _ := strings.Cut(spec, ":")
+
+	switch kind {
+	case "list":
+		if argument == "" {
+			return IntListSource(Numbers), nil
+		}
+		numbers, err := ParseNumbers(argument)
+		return ListSource(numbers), err
+	case "file":
+		return FileSource{Path: argument}, nil
+	case "csv":
+		path, column, found := strings.Cut(argument, "#")
+		if !found {
+			return nil, errors.New("csv source needs a column: csv:file.csv#column")
+		}
+		return CSVSource{Path: path, Column: column}, nil
+	case "stdin":
+		return ReaderSource{Reader: os.Stdin}, nil
+	case "env":
+		return EnvSource{Name: argument}, nil
+	}
+	return nil, fmt.Errorf("unknown source %q (use list, file, csv, stdin or env)", spec)
+}
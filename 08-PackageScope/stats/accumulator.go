@@ -0,0 +1,110 @@
+package stats
+
+import "math"
+
+// =====================
+// STREAMING ACCUMULATOR
+// =====================
+
+// Accumulator computes count, mean, variance, min and max one value at a time
+// It uses Welford's algorithm, so data never has to fit in memory
+// and the variance stays accurate even for large values
+// The zero value is ready to use: var acc stats.Accumulator
+type Accumulator struct {
+	count int     // How many values were added
+	mean  float64 // Running mean
+	m2    float64 // Sum of squared distances from the mean
+	min   float64
+	max   float64
+}
+
+// Add includes one more value in the running statistics
+// Uses pointer receiver (*Accumulator) so the totals are updated in place
+func (accumulator *Accumulator) Add(value float64) {
+	accumulator.count++
+	if accumulator.count == 1 {
+		accumulator.min, accumulator.max = value, value
+	} else {
+		accumulator.min = math.Min(accumulator.min, value)
+		accumulator.max = math.Max(accumulator.max, value)
+	}
+
+	// Welford: move the mean towards the new value, then grow m2
+	delta := value - accumulator.mean
+	accumulator.mean += delta / float64(accumulator.count)
+	accumulator.m2 += delta * (value - accumulator.mean)
+}
+
+// Merge combines another accumulator into this one
+// Useful when chunks of data are processed separately (e.g., per file)
+func (accumulator *Accumulator) Merge(other Accumulator) {
+	if other.count == 0 {
+		return
+	}
+	if accumulator.count == 0 {
+		*accumulator = other
+		return
+	}
+
+	count := accumulator.count + other.count
+	delta := other.mean - accumulator.mean
+	accumulator.mean += delta * float64(other.count) / float64(count)
+	accumulator.m2 += other.m2 + delta*delta*float64(accumulator.count)*float64(other.count)/float64(count)
+	accumulator.min = math.Min(accumulator.min, other.min)
+	accumulator.max = math.Max(accumulator.max, other.max)
+	accumulator.count = count
+}
+
+// Count returns how many values were added
+func (accumulator *Accumulator) Count() int {
+	return accumulator.count
+}
+
+// Mean returns the running mean
+func (accumulator *Accumulator) Mean() (float64, error) {
+	if accumulator.count == 0 {
+		return 0, ErrEmpty
+	}
+	return accumulator.mean, nil
+}
+
+// Variance returns the population variance of the values so far
+func (accumulator *Accumulator) Variance() (float64, error) {
+	if accumulator.count == 0 {
+		return 0, ErrEmpty
+	}
+	return accumulator.m2 / float64(accumulator.count), nil
+}
+
+// SampleVariance returns the sample variance (needs at least two values)
+func (accumulator *Accumulator) SampleVariance() (float64, error) {
+	if accumulator.count == 0 {
+		return 0, ErrEmpty
+	}
+	if accumulator.count < 2 {
+		return 0, ErrTooFew // One value has no spread to estimate
+	}
+	return accumulator.m2 / float64(accumulator.count-1), nil
+}
+
+// StdDev returns the population standard deviation of the values so far
+func (accumulator *Accumulator) StdDev() (float64, error) {
+	variance, err := accumulator.Variance()
+	return math.Sqrt(variance), err
+}
+
+// Min returns the smallest value added
+func (accumulator *Accumulator) Min() (float64, error) {
+	if accumulator.count == 0 {
+		return 0, ErrEmpty
+	}
+	return accumulator.min, nil
+}
+
+// Max returns the largest value added
+func (accumulator *Accumulator) Max() (float64, error) {
+	if accumulator.count == 0 {
+		return 0, ErrEmpty
+	}
+	return accumulator.max, nil
+}
@@ -0,0 +1,196 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// =====================
+// GENERIC NUMBER TYPE
+// =====================
+
+// Number is any integer or float type
+// The ~ means "this type or any custom type built on it" (e.g., type Age int)
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// =====================
+// ERRORS
+// =====================
+
+// Errors are returned instead of panicking (e.g., dividing by len(0))
+var (
+	ErrEmpty          = errors.New("stats: no data")
+	ErrTooFew         = errors.New("stats: need at least two values")
+	ErrWeightsLength  = errors.New("stats: values and weights have different lengths")
+	ErrZeroWeight     = errors.New("stats: weights sum to zero")
+	ErrNegativeWeight = errors.New("stats: negative weight")
+)
+
+// =====================
+// CENTRAL TENDENCY
+// =====================
+
+// Sum adds up every value as a float64 (no integer overflow or truncation)
+func Sum[T Number](data []T) float64 {
+	total := 0.0
+	for _, value := range data {
+		total += float64(value)
+	}
+	return total
+}
+
+// Mean returns the average of the data
+// Unlike integer division, Mean([]int{1, 2}) is 1.5, not 1
+func Mean[T Number](data []T) (float64, error) {
+	if len(data) == 0 {
+		return 0, ErrEmpty
+	}
+	return Sum(data) / float64(len(data)), nil
+}
+
+// WeightedMean returns sum(value*weight) / sum(weight)
+func WeightedMean[T Number, W Number](data []T, weights []W) (float64, error) {
+	if len(data) == 0 {
+		return 0, ErrEmpty
+	}
+	if len(data) != len(weights) {
+		return 0, ErrWeightsLength
+	}
+
+	total, totalWeight := 0.0, 0.0
+	for i, value := range data {
+		weight := float64(weights[i])
+		if weight < 0 {
+			return 0, ErrNegativeWeight
+		}
+		total += float64(value) * weight
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return 0, ErrZeroWeight
+	}
+	return total / totalWeight, nil
+}
+
+// Median returns the middle value (the mean of the two middle values for even lengths)
+// The input slice is not modified
+func Median[T Number](data []T) (float64, error) {
+	return Percentile(data, 50)
+}
+
+// Mode returns the most frequent values, smallest first
+// Several values are returned when they share the highest count
+func Mode[T Number](data []T) ([]T, error) {
+	if len(data) == 0 {
+		return nil, ErrEmpty
+	}
+
+	counts := map[T]int{}
+	highest := 0
+	for _, value := range data {
+		counts[value]++
+		highest = max(highest, counts[value])
+	}
+
+	modes := []T{}
+	for value, count := range counts {
+		if count == highest {
+			modes = append(modes, value)
+		}
+	}
+	sort.Slice(modes, func(i, j int) bool { return modes[i] < modes[j] })
+	return modes, nil
+}
+
+// =====================
+// SPREAD
+// =====================
+
+// Variance returns the population variance (divides by n)
+func Variance[T Number](data []T) (float64, error) {
+	var accumulator Accumulator
+	for _, value := range data {
+		accumulator.Add(float64(value))
+	}
+	return accumulator.Variance()
+}
+
+// SampleVariance returns the sample variance (divides by n-1)
+// It needs at least two values
+func SampleVariance[T Number](data []T) (float64, error) {
+	var accumulator Accumulator
+	for _, value := range data {
+		accumulator.Add(float64(value))
+	}
+	return accumulator.SampleVariance()
+}
+
+// StdDev returns the population standard deviation
+func StdDev[T Number](data []T) (float64, error) {
+	variance, err := Variance(data)
+	return math.Sqrt(variance), err
+}
+
+// SampleStdDev returns the sample standard deviation
+func SampleStdDev[T Number](data []T) (float64, error) {
+	variance, err := SampleVariance(data)
+	return math.Sqrt(variance), err
+}
+
+// =====================
+// PERCENTILES
+// =====================
+
+// Percentile returns the p-th percentile (0-100) of the data
+// It interpolates linearly between the two closest ranks
+func Percentile[T Number](data []T, p float64) (float64, error) {
+	if len(data) == 0 {
+		return 0, ErrEmpty
+	}
+	if math.IsNaN(p) || p < 0 || p > 100 {
+		return 0, fmt.Errorf("stats: percentile %v out of range 0-100", p)
+	}
+
+	// Sort a copy so the caller's slice keeps its order
+	sorted := make([]float64, len(data))
+	for i, value := range data {
+		sorted[i] = float64(value)
+	}
+	sort.Float64s(sorted)
+
+	position := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	fraction := position - float64(lower)
+	return sorted[lower] + fraction*(sorted[upper]-sorted[lower]), nil
+}
+
+// MinMax returns the smallest and largest value
+func MinMax[T Number](data []T) (T, T, error) {
+	if len(data) == 0 {
+		var zero T
+		return zero, zero, ErrEmpty
+	}
+	smallest, largest := data[0], data[0]
+	for _, value := range data[1:] {
+		smallest = min(smallest, value)
+		largest = max(largest, value)
+	}
+	return smallest, largest, nil
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// stats.Mean(data)               -> average, error on empty data
+// stats.Median(data)             -> middle value
+// stats.Mode(data)               -> most frequent value(s)
+// stats.Variance / StdDev        -> spread (population)
+// stats.Percentile(data, 90)     -> value below which 90% of the data falls
+// stats.WeightedMean(data, w)    -> average where each value counts w times
@@ -0,0 +1,271 @@
+package directory
+
+import (
+	"09-Maps/phone"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// =====================
+// PHONEBOOK SERVICE
+// =====================
+
+var (
+	ErrExists   = errors.New("directory: number already in the phonebook")
+	ErrNotFound = errors.New("directory: number not in the phonebook")
+)
+
+// Entry is one number and who it belongs to
+type Entry struct {
+	Number phone.PhoneNumber `json:"number"`
+	Name   string            `json:"name"`
+}
+
+// Phonebook finds entries by number, by number prefix and by (part of a) name
+// A map can only answer "whose number is this exactly"; the tries answer the rest
+// without looking at every entry
+type Phonebook struct {
+	numbers trie[Entry]               // Digits of the number -> entry
+	words   trie[[]phone.PhoneNumber] // Lowercase word of a name -> numbers with that word
+}
+
+// New returns an empty phonebook
+func New() *Phonebook {
+	return &Phonebook{}
+}
+
+// key is how a number is stored in the trie: the E.164 digits, then "#" and the extension
+// Numbers of one country share the first digits, so "+31" is one branch of the trie
+func key(number phone.PhoneNumber) string {
+	digits := strings.TrimPrefix(number.E164(), "+")
+	if number.Extension != "" {
+		digits += "#" + number.Extension
+	}
+	return digits
+}
+
+// words splits a name into lowercase words for the name index
+func words(name string) []string {
+	split := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	slices.Sort(split)
+	return slices.Compact(split)
+}
+
+// =====================
+// CHANGING ENTRIES
+// =====================
+
+// Insert adds a new number; it fails with ErrExists when the number is taken
+func (book *Phonebook) Insert(number phone.PhoneNumber, name string) error {
+	if _, ok := book.numbers.Get(key(number)); ok {
+		return fmt.Errorf("%w: %s", ErrExists, number)
+	}
+	book.put(Entry{Number: number, Name: name})
+	return nil
+}
+
+// Update changes the name of a number; it fails with ErrNotFound for unknown numbers
+func (book *Phonebook) Update(number phone.PhoneNumber, name string) error {
+	old, ok := book.numbers.Get(key(number))
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, number)
+	}
+	book.unindex(old)
+	book.put(Entry{Number: number, Name: name})
+	return nil
+}
+
+// Delete removes a number; it fails with ErrNotFound for unknown numbers
+func (book *Phonebook) Delete(number phone.PhoneNumber) error {
+	old, ok := book.numbers.Get(key(number))
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, number)
+	}
+	book.unindex(old)
+	book.numbers.Delete(key(number))
+	return nil
+}
+
+// put stores an entry and indexes its name
+func (book *Phonebook) put(entry Entry) {
+	book.numbers.Put(key(entry.Number), entry)
+	for _, word := range words(entry.Name) {
+		// A slice instead of a set: most words belong to a few numbers, and a
+		// million small maps would take far more memory than the numbers themselves
+		numbers, _ := book.words.Get(word)
+		book.words.Put(word, append(numbers, entry.Number))
+	}
+}
+
+// unindex removes an entry's name from the name index
+func (book *Phonebook) unindex(entry Entry) {
+	for _, word := range words(entry.Name) {
+		numbers, _ := book.words.Get(word)
+		numbers = slices.DeleteFunc(numbers, func(number phone.PhoneNumber) bool { return number == entry.Number })
+		if len(numbers) == 0 {
+			book.words.Delete(word)
+		} else {
+			book.words.Put(word, numbers)
+		}
+	}
+}
+
+// =====================
+// LOOKUPS
+// =====================
+
+// Len is the number of entries
+func (book *Phonebook) Len() int {
+	return book.numbers.Len()
+}
+
+// Lookup returns the name of a number
+func (book *Phonebook) Lookup(number phone.PhoneNumber) (string, bool) {
+	entry, ok := book.numbers.Get(key(number))
+	return entry.Name, ok
+}
+
+// Prefix returns every entry whose number starts with prefix, sorted by number
+// The prefix is international ("+31 20", "0031") or, with a region, national ("020" in "NL")
+// limit 0 means no limit
+func (book *Phonebook) Prefix(prefix string, region string, limit int) ([]Entry, error) {
+	digits, err := prefixDigits(prefix, region)
+	if err != nil {
+		return nil, err
+	}
+	found := []Entry{}
+	book.numbers.WalkPrefix(digits, func(_ string, entry Entry) bool {
+		found = append(found, entry)
+		return limit == 0 || len(found) < limit
+	})
+	return found, nil
+}
+
+// prefixDigits turns a typed prefix into the digits the trie is keyed by
+func prefixDigits(prefix string, regionCode string) (string, error) {
+	prefix = strings.TrimSpace(prefix)
+	international := strings.HasPrefix(prefix, "+")
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, prefix)
+
+	if international {
+		return digits, nil
+	}
+	region, ok := phone.Lookup(regionCode)
+	switch {
+	case ok && strings.HasPrefix(digits, region.IntlPrefix):
+		return strings.TrimPrefix(digits, region.IntlPrefix), nil
+	case strings.HasPrefix(digits, "00"):
+		return digits[2:], nil // "0031" is dialled as +31 from most countries
+	case !ok:
+		return "", fmt.Errorf("directory: give a region for the national prefix %q", prefix)
+	}
+	return fmt.Sprint(region.CountryCode) + strings.TrimPrefix(digits, region.TrunkPrefix), nil
+}
+
+// ByName returns the entries with exactly this name (ignoring case), sorted by number
+func (book *Phonebook) ByName(name string) []Entry {
+	found := []Entry{}
+	for _, entry := range book.SearchName(name) {
+		if strings.EqualFold(entry.Name, name) {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// SearchName returns the entries where every typed word starts a word of the name
+// ("mah" finds "Mahmoud", "sa ja" finds "Sara Jansen"), sorted by number
+func (book *Phonebook) SearchName(partial string) []Entry {
+	var matches map[phone.PhoneNumber]bool
+	for _, word := range words(partial) {
+		withWord := map[phone.PhoneNumber]bool{}
+		book.words.WalkPrefix(word, func(_ string, numbers []phone.PhoneNumber) bool {
+			for _, number := range numbers {
+				if matches == nil || matches[number] {
+					withWord[number] = true
+				}
+			}
+			return true
+		})
+		matches = withWord
+	}
+
+	found := make([]Entry, 0, len(matches))
+	for number := range matches {
+		entry, _ := book.numbers.Get(key(number))
+		found = append(found, entry)
+	}
+	slices.SortFunc(found, func(a, b Entry) int { return strings.Compare(key(a.Number), key(b.Number)) })
+	return found
+}
+
+// All returns every entry, sorted by number
+func (book *Phonebook) All() []Entry {
+	all := make([]Entry, 0, book.Len())
+	book.numbers.WalkPrefix("", func(_ string, entry Entry) bool {
+		all = append(all, entry)
+		return true
+	})
+	return all
+}
+
+// =====================
+// SAVING
+// =====================
+
+// Save writes every entry to a JSON file (through a temporary file, so a crash never leaves half a file)
+func (book *Phonebook) Save(path string) error {
+	data, err := json.MarshalIndent(book.All(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Load reads a phonebook saved by Save; a missing file gives an empty phonebook
+func Load(path string) (*Phonebook, error) {
+	book := New()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return book, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, entry := range entries {
+		if err := book.Insert(entry.Number, entry.Name); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return book, nil
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// book := directory.New()
+// book.Insert(number, "Sara")          -> ErrExists when taken
+// book.Update / book.Delete            -> ErrNotFound when unknown
+// book.Prefix("0031", "", 0)           -> every Dutch number
+// book.SearchName("sa")                -> names with a word starting "sa"
+// book.Save("phonebook.json")
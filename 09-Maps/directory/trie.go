@@ -0,0 +1,184 @@
+package directory
+
+import "strings"
+
+// =====================
+// RADIX TRIE
+// =====================
+
+// trie maps string keys to values and finds every key with a given prefix
+// It is a radix trie: a chain of nodes with one child each is stored as one
+// edge with a longer label, so a million phone numbers need about two million
+// nodes instead of ten million
+type trie[V any] struct {
+	root node[V]
+	size int
+}
+
+// node is one point in the trie; the key of a node is every label on the way down
+type node[V any] struct {
+	label    string     // Part of the key on the edge into this node
+	children []*node[V] // Sorted by the first byte of their label (labels of siblings never share it)
+	value    V
+	hasValue bool
+}
+
+// child returns the index of the child whose label starts with b, and whether it exists
+func (n *node[V]) child(b byte) (int, bool) {
+	for i, c := range n.children {
+		if c.label[0] == b {
+			return i, true
+		}
+		if c.label[0] > b {
+			return i, false
+		}
+	}
+	return len(n.children), false
+}
+
+// insertChild keeps children sorted
+func (n *node[V]) insertChild(index int, c *node[V]) {
+	n.children = append(n.children, nil)
+	copy(n.children[index+1:], n.children[index:])
+	n.children[index] = c
+}
+
+// commonPrefix is the length of the shared start of a and b
+func commonPrefix(a string, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// Len is the number of keys
+func (t *trie[V]) Len() int {
+	return t.size
+}
+
+// Put sets the value of a key (adding the key when it is new)
+func (t *trie[V]) Put(key string, value V) {
+	n := &t.root
+	for {
+		if key == "" {
+			if !n.hasValue {
+				t.size++
+			}
+			n.value, n.hasValue = value, true
+			return
+		}
+
+		index, ok := n.child(key[0])
+		if !ok {
+			n.insertChild(index, &node[V]{label: key, value: value, hasValue: true})
+			t.size++
+			return
+		}
+
+		c := n.children[index]
+		shared := commonPrefix(c.label, key)
+		if shared < len(c.label) {
+			// Split the edge: the shared part becomes a new node above c
+			split := &node[V]{label: c.label[:shared], children: []*node[V]{c}}
+			c.label = c.label[shared:]
+			n.children[index] = split
+			c = split
+		}
+		n = c
+		key = key[shared:]
+	}
+}
+
+// Get returns the value of a key
+func (t *trie[V]) Get(key string) (V, bool) {
+	n := &t.root
+	for key != "" {
+		index, ok := n.child(key[0])
+		if !ok || !strings.HasPrefix(key, n.children[index].label) {
+			var zero V
+			return zero, false
+		}
+		n = n.children[index]
+		key = key[len(n.label):]
+	}
+	return n.value, n.hasValue
+}
+
+// Delete removes a key; false when it was not there
+// Nodes left without a value and with one child are merged again
+func (t *trie[V]) Delete(key string) bool {
+	path := []*node[V]{&t.root}
+	n := &t.root
+	for key != "" {
+		index, ok := n.child(key[0])
+		if !ok || !strings.HasPrefix(key, n.children[index].label) {
+			return false
+		}
+		n = n.children[index]
+		key = key[len(n.label):]
+		path = append(path, n)
+	}
+	if !n.hasValue {
+		return false
+	}
+	var zero V
+	n.value, n.hasValue = zero, false
+	t.size--
+
+	// Tidy up from the bottom: drop empty leaves, merge single children
+	for i := len(path) - 1; i > 0; i-- {
+		current, parent := path[i], path[i-1]
+		switch {
+		case current.hasValue:
+			return true
+		case len(current.children) == 0:
+			index, _ := parent.child(current.label[0])
+			parent.children = append(parent.children[:index], parent.children[index+1:]...)
+		case len(current.children) == 1:
+			only := current.children[0]
+			only.label = current.label + only.label
+			index, _ := parent.child(current.label[0])
+			parent.children[index] = only
+			return true
+		default:
+			return true
+		}
+	}
+	return true
+}
+
+// WalkPrefix calls visit for every key starting with prefix, in sorted order
+// Returning false from visit stops the walk
+func (t *trie[V]) WalkPrefix(prefix string, visit func(key string, value V) bool) {
+	n := &t.root
+	key := ""
+	for prefix != "" {
+		index, ok := n.child(prefix[0])
+		if !ok {
+			return
+		}
+		c := n.children[index]
+		shared := commonPrefix(c.label, prefix)
+		if shared < len(prefix) && shared < len(c.label) {
+			return // The keys go another way
+		}
+		n = c
+		key += c.label
+		prefix = prefix[shared:]
+	}
+	walk(n, key, visit)
+}
+
+// walk visits n and everything below it; false when visit asked to stop
+func walk[V any](n *node[V], key string, visit func(string, V) bool) bool {
+	if n.hasValue && !visit(key, n.value) {
+		return false
+	}
+	for _, c := range n.children {
+		if !walk(c, key+c.label, visit) {
+			return false
+		}
+	}
+	return true
+}
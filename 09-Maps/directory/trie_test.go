@@ -0,0 +1,305 @@
+package directory
+
+import (
+	"09-Maps/phone"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// =====================
+// TRIE CORRECTNESS
+// =====================
+
+// keysWithPrefix collects what WalkPrefix visits, in visiting order
+func keysWithPrefix(t *trie[int], prefix string) []string {
+	keys := []string{}
+	t.WalkPrefix(prefix, func(key string, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// newTestTrie holds keys that share prefixes in every possible way:
+// one key inside another ("3120" in "31201"), split edges and separate branches
+func newTestTrie() *trie[int] {
+	t := &trie[int]{}
+	for i, key := range []string{"31201234567", "31201234568", "3120", "31101234567", "3220", "1201555", "31201"} {
+		t.Put(key, i)
+	}
+	return t
+}
+
+func TestTrieGet(t *testing.T) {
+	tr := newTestTrie()
+	tests := []struct {
+		key   string
+		want  int
+		found bool
+	}{
+		{"31201234567", 0, true},
+		{"31201234568", 1, true},
+		{"3120", 2, true},
+		{"31201", 6, true},
+		{"1201555", 5, true},
+		{"312", 0, false},          // Only a split point, not a key
+		{"3120123456", 0, false},   // Inside an edge
+		{"312012345679", 0, false}, // Longer than any key
+		{"4", 0, false},
+		{"", 0, false},
+	}
+	for _, test := range tests {
+		got, found := tr.Get(test.key)
+		if found != test.found || got != test.want {
+			t.Errorf("Get(%q) = %d, %t; want %d, %t", test.key, got, found, test.want, test.found)
+		}
+	}
+	if tr.Len() != 7 {
+		t.Errorf("Len() = %d, want 7", tr.Len())
+	}
+}
+
+func TestTriePutReplaces(t *testing.T) {
+	tr := newTestTrie()
+	tr.Put("3120", 99)
+	if got, _ := tr.Get("3120"); got != 99 {
+		t.Errorf("Get after replacing = %d, want 99", got)
+	}
+	if tr.Len() != 7 {
+		t.Errorf("Len() after replacing = %d, want 7", tr.Len())
+	}
+}
+
+func TestTrieWalkPrefix(t *testing.T) {
+	tr := newTestTrie()
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"1201555", "31101234567", "3120", "31201", "31201234567", "31201234568", "3220"}},
+		{"3", []string{"31101234567", "3120", "31201", "31201234567", "31201234568", "3220"}},
+		{"312", []string{"3120", "31201", "31201234567", "31201234568"}},
+		{"3120", []string{"3120", "31201", "31201234567", "31201234568"}},
+		{"312012", []string{"31201234567", "31201234568"}}, // Ends inside an edge
+		{"31201234567", []string{"31201234567"}},
+		{"312013", []string{}},        // Leaves an edge halfway
+		{"3120123456789", []string{}}, // Longer than the keys
+		{"5", []string{}},
+	}
+	for _, test := range tests {
+		if got := keysWithPrefix(tr, test.prefix); !slices.Equal(got, test.want) {
+			t.Errorf("WalkPrefix(%q) = %q, want %q", test.prefix, got, test.want)
+		}
+	}
+}
+
+func TestTrieWalkPrefixStops(t *testing.T) {
+	tr := newTestTrie()
+	visited := 0
+	tr.WalkPrefix("3", func(key string, value int) bool {
+		visited++
+		return visited < 2
+	})
+	if visited != 2 {
+		t.Errorf("visited %d keys after asking to stop at 2", visited)
+	}
+}
+
+func TestTrieDelete(t *testing.T) {
+	tr := newTestTrie()
+	if tr.Delete("312") {
+		t.Error("Delete of a split point reported true")
+	}
+	for _, key := range []string{"3120", "31201234568"} {
+		if !tr.Delete(key) {
+			t.Errorf("Delete(%q) = false", key)
+		}
+		if _, found := tr.Get(key); found {
+			t.Errorf("%q still found after Delete", key)
+		}
+	}
+	if tr.Delete("3120") {
+		t.Error("second Delete reported true")
+	}
+	want := []string{"31101234567", "31201", "31201234567", "3220"}
+	if got := keysWithPrefix(tr, "3"); !slices.Equal(got, want) {
+		t.Errorf("after Delete WalkPrefix(%q) = %q, want %q", "3", got, want)
+	}
+	if got, _ := tr.Get("31201234567"); got != 0 {
+		t.Errorf("Get of a merged key = %d, want 0", got)
+	}
+	if tr.Len() != 5 {
+		t.Errorf("Len() = %d, want 5", tr.Len())
+	}
+}
+
+// =====================
+// PHONEBOOK LOOKUPS
+// =====================
+
+func TestPhonebookLookupAndPrefix(t *testing.T) {
+	book := New()
+	entries := []struct{ number, name string }{
+		{"+31 20 123 4567", "Sara Jansen"},
+		{"+31 20 765 4321", "Mahmoud Haddad"},
+		{"+31 10 123 4567", "Piet de Vries"},
+		{"+1 201 555 0123", "Ann Lee"},
+	}
+	for _, entry := range entries {
+		if err := book.Insert(phone.MustParse(entry.number, ""), entry.name); err != nil {
+			t.Fatalf("Insert(%s): %v", entry.number, err)
+		}
+	}
+
+	if name, ok := book.Lookup(phone.MustParse("020 765 4321", "NL")); !ok || name != "Mahmoud Haddad" {
+		t.Errorf("Lookup = %q, %t; want Mahmoud Haddad", name, ok)
+	}
+	if _, ok := book.Lookup(phone.MustParse("020 765 4322", "NL")); ok {
+		t.Error("Lookup found a number that was never added")
+	}
+
+	tests := []struct {
+		prefix string
+		region string
+		want   []string
+	}{
+		{"+31 20", "", []string{"Sara Jansen", "Mahmoud Haddad"}},
+		{"0031", "", []string{"Piet de Vries", "Sara Jansen", "Mahmoud Haddad"}},
+		{"020", "NL", []string{"Sara Jansen", "Mahmoud Haddad"}},
+		{"+1", "", []string{"Ann Lee"}},
+		{"+44", "", []string{}},
+	}
+	for _, test := range tests {
+		found, err := book.Prefix(test.prefix, test.region, 0)
+		if err != nil {
+			t.Errorf("Prefix(%q, %q): %v", test.prefix, test.region, err)
+			continue
+		}
+		names := []string{}
+		for _, entry := range found {
+			names = append(names, entry.Name)
+		}
+		if !slices.Equal(names, test.want) {
+			t.Errorf("Prefix(%q, %q) = %q, want %q", test.prefix, test.region, names, test.want)
+		}
+	}
+}
+
+// =====================
+// BENCHMARKS: TRIE VS PLAIN MAP
+// =====================
+// Run with: go test ./directory -run '^$' -bench . -benchmem
+// Fewer numbers (faster): go test ./directory -run '^$' -bench . -entries 100000
+
+// benchEntries is how many numbers the benchmarks look through
+var benchEntries = flag.Int("entries", 1_000_000, "how many phone numbers the benchmarks use")
+
+// benchData builds the entries once for every benchmark: the same random numbers every run
+var benchData = sync.OnceValues(func() ([]Entry, map[phone.PhoneNumber]string) {
+	random := rand.New(rand.NewPCG(1, 2))
+	entries := make([]Entry, 0, *benchEntries)
+	plain := map[phone.PhoneNumber]string{}
+	for i := 0; len(entries) < *benchEntries; i++ {
+		// Dutch numbers: +31 and 9 digits not starting with 0
+		number := phone.PhoneNumber{CountryCode: 31, NationalNumber: fmt.Sprintf("%d%08d", 1+random.IntN(9), random.IntN(100_000_000))}
+		if _, taken := plain[number]; taken {
+			continue
+		}
+		name := fmt.Sprintf("Person%d Family%d", i, i%5000)
+		entries = append(entries, Entry{Number: number, Name: name})
+		plain[number] = name
+	}
+	return entries, plain
+})
+
+// benchBook is the trie phonebook with every benchmark entry
+var benchBook = sync.OnceValue(func() *Phonebook {
+	entries, _ := benchData()
+	book := New()
+	for _, entry := range entries {
+		book.Insert(entry.Number, entry.Name)
+	}
+	return book
+})
+
+func BenchmarkBuildMap(b *testing.B) {
+	entries, _ := benchData()
+	b.ReportAllocs()
+	for b.Loop() {
+		plain := map[phone.PhoneNumber]string{}
+		for _, entry := range entries {
+			plain[entry.Number] = entry.Name
+		}
+	}
+}
+
+func BenchmarkBuildTrie(b *testing.B) {
+	entries, _ := benchData()
+	b.ReportAllocs()
+	for b.Loop() {
+		book := New()
+		for _, entry := range entries {
+			book.Insert(entry.Number, entry.Name)
+		}
+	}
+}
+
+func BenchmarkExactMap(b *testing.B) {
+	entries, plain := benchData()
+	for i := 0; b.Loop(); i++ {
+		_ = plain[entries[i%len(entries)].Number]
+	}
+}
+
+func BenchmarkExactTrie(b *testing.B) {
+	entries, _ := benchData()
+	book := benchBook()
+	for i := 0; b.Loop(); i++ {
+		book.Lookup(entries[i%len(entries)].Number)
+	}
+}
+
+// Every number starting +31 20: the map has to look at all of them
+func BenchmarkPrefixMap(b *testing.B) {
+	_, plain := benchData()
+	for b.Loop() {
+		found := 0
+		for number := range plain {
+			if strings.HasPrefix(number.E164(), "+3120") {
+				found++
+			}
+		}
+	}
+}
+
+func BenchmarkPrefixTrie(b *testing.B) {
+	book := benchBook()
+	for b.Loop() {
+		book.Prefix("+31 20", "", 0)
+	}
+}
+
+// Reverse lookup by part of a name
+func BenchmarkNameMap(b *testing.B) {
+	_, plain := benchData()
+	for b.Loop() {
+		found := 0
+		for _, name := range plain {
+			if strings.Contains(strings.ToLower(name), "family4321") {
+				found++
+			}
+		}
+	}
+}
+
+func BenchmarkNameTrie(b *testing.B) {
+	book := benchBook()
+	for b.Loop() {
+		book.SearchName("family4321")
+	}
+}
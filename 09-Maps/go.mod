@@ -0,0 +1,3 @@
+module 09-Maps
+
+go 1.25.5
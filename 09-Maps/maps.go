@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+    "09-Maps/phone"
+    "09-Maps/directory"
+    "fmt"
+)
 
 func main() {
     // Creating a map with string keys and float64 values
@@ -19,19 +23,45 @@ func main() {
         fmt.Println(key, value)
     }
 
-    // Maps can use different types as keys (here: int keys, string values)
-    phonebook := map[int]string{
-        2675884967: "Random",
-        96700651:   "Mahmoud",
-        83828492:   "Idiot",
+    // Maps can use different types as keys (here: struct keys, string values)
+    // An int key would drop the leading 0 of "020..." and the "+" of a country code,
+    // so the phonebook is keyed by phone.PhoneNumber (a struct of comparable fields)
+    phonebook := map[phone.PhoneNumber]string{
+        phone.MustParse("(267) 588-4967", "US"):  "Random",
+        phone.MustParse("+961 96 700 651", ""):   "Mahmoud",
+        phone.MustParse("+961 83 828 492", ""):   "Idiot",
+        phone.MustParse("020 123 4567 x12", "NL"): "Office",
     }
 
-    // Access a value using an int key
-    fmt.Println(phonebook[96700651]) // Output: Mahmoud
+    // Access a value using a number typed another way: it parses to the same key
+    mahmoud := phone.MustParse("00961 96-700-651", "NL")
+    fmt.Println(phonebook[mahmoud]) // Output: Mahmoud
 
     // Update a value by assigning to an existing key
-    phonebook[96700651] = "non of your business"
+    phonebook[mahmoud] = "non of your business"
 
     // Print the updated value
-    fmt.Println(phonebook[96700651]) // Output: non of your business
-}
\ No newline at end of file
+    fmt.Println(phonebook[mahmoud]) // Output: non of your business
+
+    // One number, several ways to write it
+    office := phone.MustParse("+31201234567;ext=12", "")
+    fmt.Println(office.E164())          // Output: +31201234567
+    fmt.Println(office.International()) // Output: +31 20 123 4567 ext. 12
+    fmt.Println(office.National())      // Output: 020 123 4567 ext. 12
+    fmt.Println(office.RFC3966())       // Output: tel:+31-20-123-4567;ext=12
+
+    // Parse checks the length against the country's metadata
+    if _, err := phone.Parse("020 123 45", "NL"); err != nil {
+        fmt.Println(err) // Output: phone: wrong number of digits: Netherlands numbers have 9 digits, "02012345" has 8
+    }
+
+    // A map only finds exact keys; the directory package also searches by
+    // number prefix and by part of a name (it keeps them in tries)
+    book := directory.New()
+    for number, name := range phonebook {
+        book.Insert(number, name)
+    }
+    lebanon, _ := book.Prefix("+961", "", 0)
+    fmt.Println(len(lebanon)) // Output: 2
+    fmt.Println(book.SearchName("off")) // Output: [{+31 20 123 4567 ext. 12 Office}]
+}
@@ -0,0 +1,87 @@
+package phone
+
+import "strconv"
+
+// =====================
+// COUNTRY METADATA
+// =====================
+
+// Region is what the parser needs to know about one country's numbers
+// The table is small and kept offline: no lookups over the network
+type Region struct {
+	Code        string // ISO 3166 code, e.g., "NL"
+	Name        string
+	CountryCode int    // Calling code, e.g., 31
+	TrunkPrefix string // Dialled before national numbers ("0" in most of Europe, "1" in North America)
+	IntlPrefix  string // Dialled before international numbers ("00", "011" in North America)
+	Lengths     []int  // Valid lengths of the national significant number (without trunk prefix)
+
+	// Groups says how to split the digits when formatting, per number length
+	// A length without groups is printed in one piece
+	Groups map[int][]int
+
+	// AreaInBrackets prints the first group in brackets in the national format: (201) 555-0123
+	AreaInBrackets bool
+	Separator      string // Between groups; " " when empty
+}
+
+// regions is the offline metadata table
+// Countries sharing a calling code (US and CA) list the main one first
+var regions = []Region{
+	{Code: "US", Name: "United States", CountryCode: 1, TrunkPrefix: "1", IntlPrefix: "011", Lengths: []int{10},
+		Groups: map[int][]int{10: {3, 3, 4}}, AreaInBrackets: true, Separator: "-"},
+	{Code: "CA", Name: "Canada", CountryCode: 1, TrunkPrefix: "1", IntlPrefix: "011", Lengths: []int{10},
+		Groups: map[int][]int{10: {3, 3, 4}}, AreaInBrackets: true, Separator: "-"},
+	{Code: "GB", Name: "United Kingdom", CountryCode: 44, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{9, 10},
+		Groups: map[int][]int{9: {4, 5}, 10: {4, 6}}},
+	{Code: "NL", Name: "Netherlands", CountryCode: 31, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{9},
+		Groups: map[int][]int{9: {2, 3, 4}}},
+	{Code: "BE", Name: "Belgium", CountryCode: 32, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{8, 9},
+		Groups: map[int][]int{8: {1, 3, 2, 2}, 9: {3, 2, 2, 2}}},
+	{Code: "DE", Name: "Germany", CountryCode: 49, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{7, 8, 9, 10, 11},
+		Groups: map[int][]int{10: {3, 7}, 11: {3, 8}}},
+	{Code: "FR", Name: "France", CountryCode: 33, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{9},
+		Groups: map[int][]int{9: {1, 2, 2, 2, 2}}},
+	{Code: "EG", Name: "Egypt", CountryCode: 20, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{8, 9, 10},
+		Groups: map[int][]int{8: {1, 3, 4}, 9: {1, 4, 4}, 10: {2, 4, 4}}},
+	{Code: "LB", Name: "Lebanon", CountryCode: 961, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{7, 8},
+		Groups: map[int][]int{7: {1, 3, 3}, 8: {2, 3, 3}}},
+	{Code: "AE", Name: "United Arab Emirates", CountryCode: 971, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{8, 9},
+		Groups: map[int][]int{8: {1, 3, 4}, 9: {2, 3, 4}}},
+	{Code: "IN", Name: "India", CountryCode: 91, TrunkPrefix: "0", IntlPrefix: "00", Lengths: []int{10},
+		Groups: map[int][]int{10: {5, 5}}},
+	{Code: "AU", Name: "Australia", CountryCode: 61, TrunkPrefix: "0", IntlPrefix: "0011", Lengths: []int{9},
+		Groups: map[int][]int{9: {1, 4, 4}}},
+}
+
+// Lookup returns the region with an ISO code ("NL")
+func Lookup(code string) (Region, bool) {
+	for _, region := range regions {
+		if region.Code == code {
+			return region, true
+		}
+	}
+	return Region{}, false
+}
+
+// forCountryCode returns the main region using a calling code
+func forCountryCode(countryCode int) (Region, bool) {
+	for _, region := range regions {
+		if region.CountryCode == countryCode {
+			return region, true
+		}
+	}
+	return Region{}, false
+}
+
+// splitCountryCode finds the calling code at the start of digits
+// Calling codes are prefix-free (no code starts with another), so the first match is the only one
+func splitCountryCode(digits string) (Region, string, bool) {
+	for size := 1; size <= 3 && size < len(digits); size++ {
+		countryCode, _ := strconv.Atoi(digits[:size])
+		if region, ok := forCountryCode(countryCode); ok {
+			return region, digits[size:], true
+		}
+	}
+	return Region{}, "", false
+}
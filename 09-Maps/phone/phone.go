@@ -0,0 +1,246 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// =====================
+// PHONE NUMBER TYPE
+// =====================
+
+// PhoneNumber is a phone number split into its parts
+// An int key drops leading zeros and the "+"; this keeps everything
+// It only has comparable fields, so it can be used as a map key:
+// the same number typed in different ways gives the same key
+type PhoneNumber struct {
+	CountryCode    int    // e.g., 31 for the Netherlands
+	NationalNumber string // Digits only, without the trunk "0"
+	Extension      string // Digits after "ext." (empty = none)
+}
+
+var (
+	ErrEmpty         = errors.New("phone: empty number")
+	ErrCharacter     = errors.New("phone: number contains letters or symbols")
+	ErrUnknownRegion = errors.New("phone: unknown region or country code")
+	ErrLength        = errors.New("phone: wrong number of digits")
+)
+
+// =====================
+// PARSING
+// =====================
+
+// Parse reads a number as people write it:
+// "+31 20 123 4567", "0031 20-1234567", "(201) 555-0123 ext. 12" or "tel:+1-201-555-0123"
+// Numbers without a country code are read as numbers of defaultRegion ("NL", "US", ...)
+func Parse(text string, defaultRegion string) (PhoneNumber, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return PhoneNumber{}, ErrEmpty
+	}
+	rest, extension := cutExtension(strings.TrimPrefix(text, "tel:"))
+
+	international := strings.HasPrefix(rest, "+")
+	digits, err := onlyDigits(strings.TrimPrefix(rest, "+"))
+	if err != nil {
+		return PhoneNumber{}, err
+	}
+	if digits == "" {
+		return PhoneNumber{}, ErrEmpty
+	}
+
+	home, hasHome := Lookup(defaultRegion)
+	if !international && hasHome && home.IntlPrefix != "" && strings.HasPrefix(digits, home.IntlPrefix) {
+		// "0031..." dialled from the Netherlands, "011 31..." from the US
+		digits = strings.TrimPrefix(digits, home.IntlPrefix)
+		international = true
+	}
+
+	var region Region
+	var national string
+	if international {
+		var ok bool
+		region, national, ok = splitCountryCode(digits)
+		if !ok {
+			return PhoneNumber{}, fmt.Errorf("%w: +%s", ErrUnknownRegion, digits)
+		}
+	} else {
+		if !hasHome {
+			return PhoneNumber{}, fmt.Errorf("%w: %q (give a region for numbers without +)", ErrUnknownRegion, defaultRegion)
+		}
+		region = home
+		national = digits
+		// The trunk prefix is only dialled inside the country, so it is not part of the number
+		trimmed, hasTrunk := strings.CutPrefix(national, region.TrunkPrefix)
+		if region.TrunkPrefix != "" && hasTrunk && slices.Contains(region.Lengths, len(trimmed)) {
+			national = trimmed
+		}
+	}
+
+	if !slices.Contains(region.Lengths, len(national)) {
+		return PhoneNumber{}, fmt.Errorf("%w: %s numbers have %s digits, %q has %d", ErrLength, region.Name, describeLengths(region.Lengths), national, len(national))
+	}
+	return PhoneNumber{CountryCode: region.CountryCode, NationalNumber: national, Extension: extension}, nil
+}
+
+// MustParse is Parse for numbers known to be valid (it panics on errors)
+func MustParse(text string, defaultRegion string) PhoneNumber {
+	number, err := Parse(text, defaultRegion)
+	if err != nil {
+		panic(err)
+	}
+	return number
+}
+
+// cutExtension splits off "ext. 12", "x12", "#12" or ";ext=12"
+func cutExtension(text string) (string, string) {
+	lower := strings.ToLower(text)
+	for _, marker := range []string{";ext=", "extension", "ext.", "ext", "x", "#"} {
+		if index := strings.LastIndex(lower, marker); index > 0 {
+			extension := strings.TrimSpace(text[index+len(marker):])
+			if digits, err := onlyDigits(extension); err == nil && digits != "" {
+				return text[:index], digits
+			}
+		}
+	}
+	return text, ""
+}
+
+// onlyDigits removes the usual separators and fails on anything else
+func onlyDigits(text string) (string, error) {
+	var digits strings.Builder
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune(" -./() ", r):
+			// Separators people type; they carry no meaning
+		default:
+			return "", fmt.Errorf("%w: %q", ErrCharacter, text)
+		}
+	}
+	return digits.String(), nil
+}
+
+// describeLengths prints [9] as "9" and [7 8 9] as "7-9"
+func describeLengths(lengths []int) string {
+	first, last := lengths[0], lengths[len(lengths)-1]
+	if first == last {
+		return strconv.Itoa(first)
+	}
+	return fmt.Sprintf("%d-%d", first, last)
+}
+
+// =====================
+// FORMATTING
+// =====================
+
+// Region returns the metadata of the number's country (the main one for shared codes)
+func (number PhoneNumber) Region() Region {
+	region, _ := forCountryCode(number.CountryCode)
+	return region
+}
+
+// IsZero reports whether the number is empty (the zero value)
+func (number PhoneNumber) IsZero() bool {
+	return number == PhoneNumber{}
+}
+
+// E164 is the international standard form: "+31201234567" (no extension, no spaces)
+func (number PhoneNumber) E164() string {
+	return "+" + strconv.Itoa(number.CountryCode) + number.NationalNumber
+}
+
+// International is the form to write down for callers abroad: "+31 20 123 4567"
+func (number PhoneNumber) International() string {
+	region := number.Region()
+	formatted := "+" + strconv.Itoa(number.CountryCode) + " " + strings.Join(group(region, number.NationalNumber), separator(region))
+	return formatted + number.extensionSuffix()
+}
+
+// National is the form dialled inside the country: "020 123 4567" or "(201) 555-0123"
+func (number PhoneNumber) National() string {
+	region := number.Region()
+	groups := group(region, number.NationalNumber)
+	if region.AreaInBrackets && len(groups) > 1 {
+		return "(" + groups[0] + ") " + strings.Join(groups[1:], separator(region)) + number.extensionSuffix()
+	}
+	groups[0] = region.TrunkPrefix + groups[0]
+	return strings.Join(groups, separator(region)) + number.extensionSuffix()
+}
+
+// RFC3966 is the "tel:" URI form used in links and vCards: "tel:+31-20-123-4567;ext=12"
+func (number PhoneNumber) RFC3966() string {
+	uri := "tel:+" + strconv.Itoa(number.CountryCode) + "-" + strings.Join(group(number.Region(), number.NationalNumber), "-")
+	if number.Extension != "" {
+		uri += ";ext=" + number.Extension
+	}
+	return uri
+}
+
+// String implements fmt.Stringer with the international form
+func (number PhoneNumber) String() string {
+	return number.International()
+}
+
+// MarshalText saves the number as E.164 plus ";ext=" (used by encoding/json)
+func (number PhoneNumber) MarshalText() ([]byte, error) {
+	text := number.E164()
+	if number.Extension != "" {
+		text += ";ext=" + number.Extension
+	}
+	return []byte(text), nil
+}
+
+// UnmarshalText reads what MarshalText wrote
+func (number *PhoneNumber) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text), "")
+	if err != nil {
+		return err
+	}
+	*number = parsed
+	return nil
+}
+
+// group splits the digits as the region writes them
+func group(region Region, digits string) []string {
+	sizes, ok := region.Groups[len(digits)]
+	if !ok {
+		return []string{digits}
+	}
+	groups := []string{}
+	for _, size := range sizes {
+		groups = append(groups, digits[:size])
+		digits = digits[size:]
+	}
+	return groups
+}
+
+// separator is what goes between groups in a region
+func separator(region Region) string {
+	if region.Separator == "" {
+		return " "
+	}
+	return region.Separator
+}
+
+// extensionSuffix is " ext. 12", or nothing without an extension
+func (number PhoneNumber) extensionSuffix() string {
+	if number.Extension == "" {
+		return ""
+	}
+	return " ext. " + number.Extension
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// phone.Parse("020 123 4567", "NL")  -> PhoneNumber{31, "201234567", ""}
+// number.E164()                      -> "+31201234567"
+// number.International()             -> "+31 20 123 4567"
+// number.National()                  -> "020 123 4567"
+// number.RFC3966()                   -> "tel:+31-20-123-4567"
+// map[phone.PhoneNumber]string{}     -> phonebook keyed by the number
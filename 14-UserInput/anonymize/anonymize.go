@@ -0,0 +1,541 @@
+package anonymize
+
+import (
+	"14-UserInput/fuzzy"
+	"14-UserInput/gazetteer"
+	"14-UserInput/structs"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// =====================
+// ANONYMIZED EXPORT
+// =====================
+// Turns the people into a table that can be shared with analysts:
+//   1. Direct identifiers (UID, birthday, email, phone, address, ...) are dropped
+//   2. Names become pseudonyms: the same person gets the same one in every export
+//   3. Ages become ranges ("30-39")
+//   4. Rare values are made coarser (wider age ranges, a city becomes its country), then replaced by "*"
+//      until every record looks exactly like at least K-1 others (k-anonymity)
+// Whatever could still single someone out is withheld, and the report says how much was lost
+
+// Suppressed replaces a value that would make a record stand out
+const Suppressed = "*"
+
+// AgeColumn is the name of the age range column
+const AgeColumn = "Age"
+
+// Options controls the export
+type Options struct {
+	K         int                  // Every record must look like at least K-1 others
+	AgeBucket int                  // Width of the age ranges in years (10 -> 30-39)
+	Secret    []byte               // Key for the pseudonyms; the same key gives the same pseudonyms
+	Places    *gazetteer.Gazetteer // Makes cities coarser by replacing them with their country (optional)
+}
+
+// DefaultOptions returns sensible export settings (Secret still has to be set)
+func DefaultOptions() Options {
+	return Options{K: 5, AgeBucket: 10}
+}
+
+// Record is one exported person
+type Record struct {
+	Pseudonym string
+	Values    map[string]string // Column -> value ("" when the person has none)
+}
+
+// Dataset is the anonymized table and what it cost
+type Dataset struct {
+	Columns []string // AgeColumn first, then the Information keys that were kept
+	Records []Record // Sorted by pseudonym, so the order says nothing about the roster
+	Report  Report
+}
+
+// Report says how much detail the export lost
+type Report struct {
+	K           int
+	AgeBucket   int
+	People      int            // Records in
+	Withheld    int            // Records that could not be hidden among K and were left out
+	Dropped     map[string]int // Identifier -> values removed
+	Generalized int            // Exported values made coarser (wider age range, country for city)
+	Suppressed  map[string]int // Column -> exported values replaced by "*"
+	Cells       int            // Values in the exported records (records x columns)
+	Before      map[string]int // Column -> distinct values before
+	After       map[string]int // Column -> distinct values after
+	Groups      []int          // Sizes of the groups of identical records
+}
+
+// =====================
+// DIRECT IDENTIFIERS
+// =====================
+
+// identifierKeys are Information keys that point at one person on their own
+var identifierKeys = []string{
+	"name", "full name", "first name", "last name", "surname", "nickname",
+	"email", "e-mail", "mail", "phone", "mobile", "cell", "tel", "telephone", "fax",
+	"address", "street", "apartment", "house number", "postcode", "postal code", "zip", "zip code",
+	"website", "url", "homepage", "linkedin", "twitter", "instagram", "facebook", "github",
+	"passport", "ssn", "bsn", "social security", "iban", "account", "id", "license plate",
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	urlPattern   = regexp.MustCompile(`(?i)^(https?://|www\.)`)
+	numberSuffix = regexp.MustCompile(`\s+\d+$`) // "email 2" is still an email
+)
+
+// IsIdentifier reports whether an Information entry identifies a person on its own:
+// by its key ("email", "Phone 2", "adress") or by its value (an email address,
+// a web address, or a number with 7 digits or more such as a phone or account number)
+func IsIdentifier(key string, value string) bool {
+	base := numberSuffix.ReplaceAllString(strings.TrimSpace(key), "")
+	for _, known := range identifierKeys {
+		if fuzzy.Close(base, known) {
+			return true
+		}
+	}
+	digits := 0
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return emailPattern.MatchString(value) || urlPattern.MatchString(value) || digits >= 7
+}
+
+// Pseudonym is the stable stand-in for a person's name: an HMAC of the UID,
+// so it cannot be turned back into the person without the secret
+func Pseudonym(secret []byte, person structs.Person) string {
+	id := person.UID
+	if id == "" {
+		id = person.Name // People that were never saved have no UID yet
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(id))
+	return "P-" + hex.EncodeToString(mac.Sum(nil))[:10]
+}
+
+// AgeRange puts an age in a range of width years ("30-39"); 0 is unknown
+func AgeRange(age int, width int) string {
+	if age <= 0 {
+		return ""
+	}
+	width = max(width, 1)
+	from := age / width * width
+	if width == 1 {
+		return strconv.Itoa(age)
+	}
+	return strconv.Itoa(from) + "-" + strconv.Itoa(from+width-1)
+}
+
+// =====================
+// EXPORT
+// =====================
+
+// Export anonymizes people
+func Export(people []structs.Person, options Options) (Dataset, error) {
+	if options.K < 1 {
+		return Dataset{}, fmt.Errorf("anonymize: k must be at least 1, got %d", options.K)
+	}
+	if len(options.Secret) == 0 {
+		return Dataset{}, fmt.Errorf("anonymize: a secret is needed for the pseudonyms")
+	}
+
+	report := Report{
+		K:          options.K,
+		AgeBucket:  options.AgeBucket,
+		People:     len(people),
+		Dropped:    map[string]int{},
+		Suppressed: map[string]int{},
+		Before:     map[string]int{},
+		After:      map[string]int{},
+	}
+
+	// "Location" and "locaton" must be one column, or the typo alone tells who it is
+	// Only keys are merged: a value close to another may be a different word ("Essex", "Essen")
+	index := fuzzy.NewIndex(people)
+	for _, group := range index.KeyGroups() {
+		if !group.Tied {
+			index.Accept("", group, group.Canonical.Spelling)
+		}
+	}
+	records := make([]Record, 0, len(people))
+	keys := map[string]bool{}
+	for _, person := range people {
+		person, _ = index.Normalize(person)
+		record := Record{
+			Pseudonym: Pseudonym(options.Secret, person),
+			Values:    map[string]string{AgeColumn: AgeRange(person.Age, options.AgeBucket)},
+		}
+		report.Dropped["Name"]++
+		if person.UID != "" {
+			report.Dropped["UID"]++
+		}
+		if person.Birthday != "" {
+			report.Dropped["Birthday"]++
+		}
+		if len(person.Attachments) > 0 {
+			report.Dropped["Attachments"] += len(person.Attachments)
+		}
+		for key, value := range person.Information {
+			if strings.EqualFold(key, AgeColumn) {
+				continue // The age is already there, as a range
+			}
+			if IsIdentifier(key, value) {
+				report.Dropped[numberSuffix.ReplaceAllString(key, "")]++
+				continue
+			}
+			if options.Places != nil && gazetteer.IsLocationKey(key) {
+				// One spelling per place is not a loss, but only for a place the
+				// gazetteer knows by name: a guess could turn "Essex" into "Essen"
+				if match, ok := options.Places.Resolve(value); ok && !match.Partial && !match.Guessed {
+					value = match.Place.Label()
+				}
+			}
+			record.Values[key] = value
+			keys[key] = true
+		}
+		records = append(records, record)
+	}
+
+	columns := []string{AgeColumn}
+	for key := range keys {
+		columns = append(columns, key)
+	}
+	sort.Strings(columns[1:])
+	for _, column := range columns {
+		report.Before[column] = distinct(records, column)
+	}
+
+	generalized := map[string]int{} // Pseudonym -> cities replaced
+	records, report.Withheld = options.anonymize(records, columns, generalized)
+
+	sort.Slice(records, func(i, j int) bool { return records[i].Pseudonym < records[j].Pseudonym })
+	for _, column := range columns {
+		report.After[column] = distinct(records, column)
+	}
+	for _, record := range records {
+		report.Generalized += generalized[record.Pseudonym]
+		for _, column := range columns {
+			if record.Values[column] == Suppressed {
+				report.Suppressed[column]++
+			}
+		}
+	}
+	report.Cells = len(records) * len(columns)
+	for _, members := range groupRecords(records, columns) {
+		report.Groups = append(report.Groups, len(members))
+	}
+	sort.Ints(report.Groups)
+	return Dataset{Columns: columns, Records: records, Report: report}, nil
+}
+
+// anonymize makes the records k-anonymous and returns the ones that can be shared,
+// and how many were withheld; generalized counts the values made coarser per record
+func (options Options) anonymize(records []Record, columns []string, generalized map[string]int) ([]Record, int) {
+	// A value fewer than K people have always singles them out:
+	// make it coarser while that is possible, then suppress it
+	for _, column := range columns {
+		for changed := true; changed; {
+			changed = false
+			counts := valueCounts(records, column)
+			for _, record := range records {
+				if counts[record.Values[column]] >= options.K {
+					continue
+				}
+				if coarser, ok := options.coarser(column, record.Values[column]); ok {
+					record.Values[column] = coarser
+					generalized[record.Pseudonym]++
+					changed = true
+				}
+			}
+		}
+		counts := valueCounts(records, column)
+		for _, record := range records {
+			if counts[record.Values[column]] < options.K {
+				record.Values[column] = Suppressed
+			}
+		}
+	}
+
+	// Records can still be unique by their combination of values ("30-39" and "Amsterdam, NL").
+	// Change one column in those records only, making it coarser or suppressing it:
+	// the change that leaves the fewest records in too small groups wins
+	// (ties: making coarser before suppressing, then the fewest values changed)
+	for {
+		small := smallRecords(records, columns, options.K)
+		if len(small) == 0 {
+			return records, 0
+		}
+
+		type change struct {
+			column  string
+			coarser bool
+		}
+		var best change
+		bestSmall, bestCost, found := 0, 0, false
+		for _, column := range columns {
+			for _, coarser := range []bool{true, false} {
+				candidate := change{column, coarser}
+				saved := options.apply(small, candidate.column, candidate.coarser)
+				if len(saved) == 0 {
+					continue // Nothing to change in this column
+				}
+				left := len(smallRecords(records, columns, options.K))
+				cost := 2 * len(saved)
+				if coarser {
+					cost = len(saved) // Coarser keeps some detail, so it costs less
+				}
+				for _, record := range small {
+					if value, ok := saved[record.Pseudonym]; ok {
+						record.Values[column] = value
+					}
+				}
+				if !found || left < bestSmall || left == bestSmall && cost < bestCost {
+					best, bestSmall, bestCost, found = candidate, left, cost, true
+				}
+			}
+		}
+		if !found {
+			// Everything is suppressed and the group is still too small: leave them out
+			withheld := map[string]bool{}
+			for _, record := range small {
+				withheld[record.Pseudonym] = true
+			}
+			kept := []Record{}
+			for _, record := range records {
+				if !withheld[record.Pseudonym] {
+					kept = append(kept, record)
+				}
+			}
+			return kept, len(small)
+		}
+		for pseudonym := range options.apply(small, best.column, best.coarser) {
+			if best.coarser {
+				generalized[pseudonym]++
+			}
+		}
+	}
+}
+
+// apply makes a column coarser or suppresses it in records,
+// and returns the old values of the records that changed (by pseudonym)
+func (options Options) apply(records []Record, column string, coarser bool) map[string]string {
+	saved := map[string]string{}
+	for _, record := range records {
+		value := record.Values[column]
+		next, ok := Suppressed, value != Suppressed
+		if coarser {
+			next, ok = options.coarser(column, value)
+		}
+		if ok {
+			saved[record.Pseudonym] = value
+			record.Values[column] = next
+		}
+	}
+	return saved
+}
+
+// coarser returns a less precise value that still says something:
+// an age range twice as wide ("30-39" -> "20-39", up to 80 years),
+// or the country of a city ("Amsterdam, NL" -> "Netherlands")
+func (options Options) coarser(column string, value string) (string, bool) {
+	if column == AgeColumn {
+		fromText, toText, found := strings.Cut(value, "-")
+		from, fromErr := strconv.Atoi(fromText)
+		to, toErr := strconv.Atoi(toText)
+		width := 2 * (to - from + 1)
+		if !found || fromErr != nil || toErr != nil || width > 80 {
+			return "", false
+		}
+		from = from / width * width
+		return strconv.Itoa(from) + "-" + strconv.Itoa(from+width-1), true
+	}
+	if options.Places == nil || !gazetteer.IsLocationKey(column) {
+		return "", false
+	}
+	match, ok := options.Places.Resolve(value)
+	if !ok || match.Partial || match.Guessed || match.Place.Kind != gazetteer.City {
+		return "", false
+	}
+	country, ok := options.Places.Country(match.Place.Country)
+	return country.Label(), ok
+}
+
+// smallRecords lists the records in groups of fewer than k identical records
+func smallRecords(records []Record, columns []string, k int) []Record {
+	small := []Record{}
+	for _, members := range groupRecords(records, columns) {
+		if len(members) < k {
+			small = append(small, members...)
+		}
+	}
+	return small
+}
+
+// groupRecords puts records with the same values in every column together
+func groupRecords(records []Record, columns []string) [][]Record {
+	byKey := map[string][]Record{}
+	order := []string{}
+	for _, record := range records {
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = record.Values[column]
+		}
+		key := strings.Join(values, "\x00")
+		if _, seen := byKey[key]; !seen {
+			order = append(order, key)
+		}
+		byKey[key] = append(byKey[key], record)
+	}
+	groups := make([][]Record, 0, len(order))
+	for _, key := range order {
+		groups = append(groups, byKey[key])
+	}
+	return groups
+}
+
+// valueCounts counts how many records have each value in a column
+func valueCounts(records []Record, column string) map[string]int {
+	counts := map[string]int{}
+	for _, record := range records {
+		counts[record.Values[column]]++
+	}
+	return counts
+}
+
+// distinct counts the different values of a column, "*" and empty not included
+func distinct(records []Record, column string) int {
+	count := 0
+	for value := range valueCounts(records, column) {
+		if value != "" && value != Suppressed {
+			count++
+		}
+	}
+	return count
+}
+
+// =====================
+// OUTPUT
+// =====================
+
+// WriteCSV writes the table with a header row: Pseudonym, Age, then the other columns
+func (dataset Dataset) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append([]string{"Pseudonym"}, dataset.Columns...)); err != nil {
+		return err
+	}
+	for _, record := range dataset.Records {
+		row := []string{record.Pseudonym}
+		for _, column := range dataset.Columns {
+			row = append(row, record.Values[column])
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Format prints the report, ending with the share of detail that was lost
+func (report Report) Format() string {
+	var builder strings.Builder
+	line := func(label string, format string, args ...any) {
+		fmt.Fprintf(&builder, "  %-13s %s\n", label+":", fmt.Sprintf(format, args...))
+	}
+	exported := report.People - report.Withheld
+
+	others := "others"
+	if report.K == 2 {
+		others = "other"
+	}
+	fmt.Fprintf(&builder, "Anonymized %d people (k = %d: every record looks like at least %d %s)\n", report.People, report.K, report.K-1, others)
+	line("Exported", "%d records, %d withheld (could not be hidden among %d)", exported, report.Withheld, report.K)
+
+	dropped := []string{}
+	for _, key := range sortedByCount(report.Dropped) {
+		note := ""
+		if key == "Name" {
+			note = ", replaced by pseudonyms"
+		}
+		dropped = append(dropped, fmt.Sprintf("%s (%d%s)", key, report.Dropped[key], note))
+	}
+	if len(dropped) == 0 {
+		dropped = append(dropped, "nothing")
+	}
+	line("Removed", "%s", strings.Join(dropped, ", "))
+	line("Ages", "%d-year ranges", max(report.AgeBucket, 1))
+	if report.Generalized > 0 {
+		line("Generalized", "%d values made coarser (wider age ranges, countries for cities)", report.Generalized)
+	}
+
+	suppressed, total := []string{}, 0
+	for _, column := range sortedByCount(report.Suppressed) {
+		suppressed = append(suppressed, fmt.Sprintf("%s %d", column, report.Suppressed[column]))
+		total += report.Suppressed[column]
+	}
+	if total > 0 {
+		line("Suppressed", "%d values replaced by %q: %s", total, Suppressed, strings.Join(suppressed, ", "))
+	} else {
+		line("Suppressed", "nothing")
+	}
+
+	precision := []string{}
+	for _, column := range sortedKeys(report.Before) {
+		if report.Before[column] != report.After[column] {
+			precision = append(precision, fmt.Sprintf("%s %d -> %d", column, report.Before[column], report.After[column]))
+		}
+	}
+	if len(precision) > 0 {
+		line("Distinct", "%s", strings.Join(precision, ", "))
+	}
+	if len(report.Groups) > 0 {
+		line("Groups", "%d of identical records, smallest %d, average %.1f", len(report.Groups), report.Groups[0], float64(exported)/float64(len(report.Groups)))
+	}
+
+	lost, cellsLost := 0.0, 0.0
+	if report.People > 0 {
+		lost = 100 * float64(report.Withheld) / float64(report.People)
+	}
+	if report.Cells > 0 {
+		cellsLost = 100 * float64(total) / float64(report.Cells)
+	}
+	line("Detail lost", "%.1f%% of the records, %.1f%% of the exported values", lost, cellsLost)
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// sortedByCount returns the keys of a count map, largest count first
+func sortedByCount(counts map[string]int) []string {
+	keys := sortedKeys(counts)
+	sort.SliceStable(keys, func(i, j int) bool { return counts[keys[i]] > counts[keys[j]] })
+	return keys
+}
+
+// sortedKeys returns the keys of a count map in order
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// anonymize.Export(people, options) -> Dataset: columns, records and report
+// dataset.WriteCSV(file)             -> table for the analysts
+// dataset.Report.Format()            -> what was removed, suppressed and withheld
+// anonymize.Pseudonym(secret, p)     -> "P-3f2a9c1b7e", the same every time
+// anonymize.AgeRange(34, 10)         -> "30-39"
@@ -0,0 +1,188 @@
+package blobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// =====================
+// CONTENT-ADDRESSED BLOB STORE
+// =====================
+// Every file is saved under the SHA-256 hash of its content:
+//   people.json.blobs/3f/3f2a9c...e1
+// The same photo attached twice is stored once, and a blob never changes:
+// a different content is a different hash, so a different file
+
+// ErrNotFound means there is no blob with that hash
+var ErrNotFound = errors.New("blobs: not found")
+
+// Store is a directory of blobs
+type Store struct {
+	dir string
+}
+
+// Open returns the store in dir (created on the first Put)
+func Open(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir returns the directory the blobs are in
+func (store *Store) Dir() string {
+	return store.dir
+}
+
+// path is where a blob lives: the first two hex digits are a subdirectory,
+// so no directory ever holds too many files
+func (store *Store) path(hash string) string {
+	return filepath.Join(store.dir, hash[:2], hash)
+}
+
+// validHash reports whether hash is 64 lowercase hex digits
+func validHash(hash string) bool {
+	if len(hash) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(hash)
+	return err == nil && strings.ToLower(hash) == hash
+}
+
+// Put copies r into the store and returns its hash and size
+// The data goes to a temporary file first and is renamed once the hash is known
+func (store *Store) Put(r io.Reader) (string, int64, error) {
+	if err := os.MkdirAll(store.dir, 0755); err != nil {
+		return "", 0, err
+	}
+	tmp, err := os.CreateTemp(store.dir, "incoming-*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name()) // Fails harmlessly after the rename
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	if _, err := os.Stat(store.path(hash)); err == nil {
+		return hash, size, nil // Already stored
+	}
+	if err := os.MkdirAll(filepath.Dir(store.path(hash)), 0755); err != nil {
+		return "", 0, err
+	}
+	return hash, size, os.Rename(tmp.Name(), store.path(hash))
+}
+
+// Get opens a blob for reading
+func (store *Store) Get(hash string) (io.ReadCloser, error) {
+	if !validHash(hash) {
+		return nil, fmt.Errorf("%w: %q", ErrNotFound, hash)
+	}
+	file, err := os.Open(store.path(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, hash)
+	}
+	return file, err
+}
+
+// Verify reads a blob again and checks that its content still has its hash
+func (store *Store) Verify(hash string) error {
+	file, err := store.Get(hash)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(hasher.Sum(nil)); got != hash {
+		return fmt.Errorf("blobs: %s is damaged (content hashes to %s)", hash, got)
+	}
+	return nil
+}
+
+// DetectType guesses the MIME type from the file name, then from the content
+// head is the start of the file (http.DetectContentType looks at 512 bytes at most)
+func DetectType(name string, head []byte) string {
+	if byExtension := mime.TypeByExtension(strings.ToLower(filepath.Ext(name))); byExtension != "" {
+		return byExtension
+	}
+	return http.DetectContentType(head)
+}
+
+// =====================
+// GARBAGE COLLECTION
+// =====================
+
+// GCResult says what a garbage collection removed
+type GCResult struct {
+	Removed []string // Hashes of the removed blobs
+	Freed   int64    // Bytes
+}
+
+// TempMaxAge is how long a temporary file may go unchanged before GC removes it
+// A Put that is still copying writes to its file all the time, so it is never this old
+const TempMaxAge = time.Hour
+
+// GC removes every blob whose hash is not in referenced,
+// and temporary files left behind by an interrupted Put (older than TempMaxAge)
+func (store *Store) GC(referenced map[string]bool) (GCResult, error) {
+	result := GCResult{}
+	err := filepath.WalkDir(store.dir, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == store.dir {
+			return fs.SkipAll // No blobs yet
+		}
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		name := entry.Name()
+		isBlob := validHash(name)
+		if (isBlob && referenced[name]) || (!isBlob && !strings.HasSuffix(name, ".tmp")) {
+			return nil // In use, or not ours
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if !isBlob && time.Since(info.ModTime()) < TempMaxAge {
+			return nil // Maybe a Put that is still running
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		if isBlob {
+			result.Removed = append(result.Removed, name)
+			os.Remove(filepath.Dir(path)) // Only succeeds once the subdirectory is empty
+		}
+		result.Freed += info.Size()
+		return nil
+	})
+	return result, err
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// blobs.Open("people.json.blobs") -> blob store in a directory
+// store.Put(file)                 -> hash and size (stored once per content)
+// store.Get(hash)                 -> read a blob back
+// store.GC(referenced)            -> delete blobs nobody points to
+// blobs.DetectType(name, head)    -> "image/jpeg", "application/pdf", ...
@@ -0,0 +1,94 @@
+package calendar
+
+import (
+	"14-UserInput/structs"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =====================
+// UPCOMING BIRTHDAYS
+// =====================
+
+// Birthday is the next birthday of one person
+type Birthday struct {
+	Person structs.Person
+	Date   time.Time // The day it is celebrated (local midnight)
+	Turns  int       // Age on that day
+	InDays int       // 0 = today
+}
+
+// Upcoming returns the birthdays from today up to days ahead, soonest first
+// People without a (valid) birthday are skipped
+func Upcoming(people []structs.Person, today time.Time, days int) []Birthday {
+	today = dayOf(today)
+	birthdays := []Birthday{}
+	for _, person := range people {
+		born, ok := person.BirthDate()
+		if !ok {
+			continue
+		}
+		next := celebrated(born, today.Year(), today.Location())
+		if next.Before(today) {
+			next = celebrated(born, today.Year()+1, today.Location())
+		}
+		inDays := daysBetween(today, next)
+		if inDays > days {
+			continue
+		}
+		birthdays = append(birthdays, Birthday{Person: person, Date: next, Turns: next.Year() - born.Year(), InDays: inDays})
+	}
+	sort.SliceStable(birthdays, func(i, j int) bool {
+		if birthdays[i].InDays != birthdays[j].InDays {
+			return birthdays[i].InDays < birthdays[j].InDays
+		}
+		return birthdays[i].Person.Name < birthdays[j].Person.Name
+	})
+	return birthdays
+}
+
+// celebrated returns the birthday in a year
+// People born on February 29 celebrate on February 28 in other years
+func celebrated(born time.Time, year int, location *time.Location) time.Time {
+	month, day := born.Month(), born.Day()
+	if month == time.February && day == 29 && !isLeapYear(year) {
+		day = 28
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, location)
+}
+
+// isLeapYear reports whether February has 29 days in a year
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// dayOf drops the time of day
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts calendar days; rounding hides daylight saving hours
+func daysBetween(from time.Time, to time.Time) int {
+	return int((to.Sub(from).Hours() + 12) / 24)
+}
+
+// FormatUpcoming prints one line per birthday
+func FormatUpcoming(birthdays []Birthday, days int) string {
+	if len(birthdays) == 0 {
+		return fmt.Sprintf("No birthdays in the next %d days.", days)
+	}
+	var builder strings.Builder
+	for _, birthday := range birthdays {
+		when := fmt.Sprintf("in %d days", birthday.InDays)
+		switch birthday.InDays {
+		case 0:
+			when = "today"
+		case 1:
+			when = "tomorrow"
+		}
+		fmt.Fprintf(&builder, "%s  %-12s %s turns %d\n", birthday.Date.Format("Mon Jan 02"), when, birthday.Person.Name, birthday.Turns)
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
@@ -0,0 +1,108 @@
+package calendar
+
+import (
+	"14-UserInput/structs"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// =====================
+// ICALENDAR EXPORT (RFC 5545)
+// =====================
+
+// Export returns an iCalendar file with one yearly event per birthday
+// now is written as DTSTAMP (when the file was made)
+func Export(people []structs.Person, now time.Time) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//14-UserInput//Birthdays//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Birthdays",
+	}
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, person := range people {
+		born, ok := person.BirthDate()
+		if !ok {
+			continue
+		}
+		uid := person.UID
+		if uid == "" {
+			uid = born.Format("20060102") + "-" + strings.ReplaceAll(strings.ToLower(person.Name), " ", "-")
+		}
+
+		// An all-day event (VALUE=DATE) on the birth date, repeated every year
+		// DTEND is the next day: the end of an all-day event is exclusive
+		rule := "FREQ=YEARLY"
+		if born.Month() == time.February && born.Day() == 29 {
+			rule = "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1" // The last day of February: the 28th in other years
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escape(uid)+"-birthday",
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+born.Format("20060102"),
+			"DTEND;VALUE=DATE:"+born.AddDate(0, 0, 1).Format("20060102"),
+			"RRULE:"+rule,
+			"SUMMARY:"+escape(person.Name+"'s birthday"),
+			"DESCRIPTION:"+escape("Born "+person.Birthday),
+			"TRANSP:TRANSPARENT", // Does not make anyone look busy
+			"CATEGORIES:BIRTHDAY",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var calendar strings.Builder
+	for _, line := range lines {
+		calendar.WriteString(fold(line))
+	}
+	return calendar.String()
+}
+
+// Handler serves the current export, so a calendar app can subscribe to it
+// The people are loaded again on every request (load is usually store.Open)
+func Handler(load func() ([]structs.Person, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		people, err := load()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Write([]byte(Export(people, time.Now())))
+	})
+}
+
+// escape protects the characters with a meaning in TEXT values (RFC 5545 section 3.3.11)
+func escape(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return replacer.Replace(text)
+}
+
+// fold ends a line with CRLF and breaks it every 75 octets (RFC 5545 section 3.1)
+// Breaks never fall inside a UTF-8 character
+func fold(line string) string {
+	var folded strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut-- // A continuation byte: move back to the start of the character
+		}
+		folded.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // The leading space counts too
+	}
+	folded.WriteString(line + "\r\n")
+	return folded.String()
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// calendar.Upcoming(people, time.Now(), 30) -> birthdays in the next 30 days
+// calendar.Export(people, time.Now())       -> .ics text, one yearly VEVENT per person
+// calendar.Handler(load)                    -> serve the .ics for subscriptions
@@ -0,0 +1,706 @@
+package carddav
+
+import (
+	"14-UserInput/store"
+	"14-UserInput/structs"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// =====================
+// CARDDAV SERVER (RFC 6352)
+// =====================
+// The subset phones and mail clients use:
+//   OPTIONS, PROPFIND        -> discover the address book and list its cards
+//   REPORT                   -> addressbook-query, addressbook-multiget, sync-collection
+//   GET, PUT, DELETE         -> read, write and delete single vCards (with ETags)
+//
+// URLs:
+//   /principals/people/          the (only) user
+//   /addressbooks/               the user's address book home
+//   /addressbooks/people/        the address book, backed by the person store
+//   /addressbooks/people/<uid>.vcf  one person
+
+const (
+	principalPath = "/principals/people/"
+	homePath      = "/addressbooks/"
+	bookPath      = "/addressbooks/people/"
+)
+
+// XML namespaces and the prefixes the responses use for them
+const (
+	nsDAV     = "DAV:"
+	nsCardDAV = "urn:ietf:params:xml:ns:carddav"
+	nsCS      = "http://calendarserver.org/ns/" // getctag, still asked for by many clients
+)
+
+var prefixes = map[string]string{nsDAV: "d", nsCardDAV: "card", nsCS: "cs"}
+
+// Server serves the people of a store file as an address book
+// The store is read again on every request, so people added with the CLI show up
+type Server struct {
+	storePath string
+	lock      sync.Mutex // One request at a time touches the files
+}
+
+// NewServer returns a server for the store at storePath
+// The sync state is kept next to it, in storePath + ".carddav"
+func NewServer(storePath string) *Server {
+	return &Server{storePath: storePath}
+}
+
+// ServeHTTP implements http.Handler
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+
+	if r.URL.Path == "/.well-known/carddav" {
+		http.Redirect(w, r, principalPath, http.StatusMovedPermanently)
+		return
+	}
+
+	people, state, err := server.load()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("DAV", "1, 3, addressbook")
+	switch r.Method {
+	case "OPTIONS":
+		w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		w.WriteHeader(http.StatusOK)
+	case "PROPFIND":
+		server.propfind(w, r, people, state)
+	case "REPORT":
+		server.report(w, r, people, state)
+	case "GET", "HEAD":
+		server.get(w, r, people, state)
+	case "PUT":
+		server.put(w, r, people, state)
+	case "DELETE":
+		server.delete(w, r, people, state)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// load opens the store and the sync state, and brings the state up to date
+func (server *Server) load() (*store.Store, *syncState, error) {
+	people, err := store.Open(server.storePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := loadState(server.storePath + ".carddav")
+	if err != nil {
+		return nil, nil, err
+	}
+	if state.refresh(people.People) {
+		if err := state.save(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return people, state, nil
+}
+
+// commit saves the store after a PUT or DELETE and moves the sync state along
+func (server *Server) commit(people *store.Store, state *syncState) error {
+	if err := people.Save(); err != nil {
+		return err
+	}
+	state.refresh(people.People)
+	return state.save()
+}
+
+// =====================
+// SINGLE CARDS: GET, PUT, DELETE
+// =====================
+
+// cardUID returns the UID in "/addressbooks/people/<uid>.vcf"
+func cardUID(path string) (string, bool) {
+	name, found := strings.CutPrefix(path, bookPath)
+	if !found || !strings.HasSuffix(name, ".vcf") || strings.Contains(name, "/") {
+		return "", false
+	}
+	uid := strings.TrimSuffix(name, ".vcf")
+	return uid, uid != ""
+}
+
+// cardHref is the URL of a person's card
+func cardHref(uid string) string {
+	return bookPath + url.PathEscape(uid) + ".vcf"
+}
+
+// get sends one vCard
+func (server *Server) get(w http.ResponseWriter, r *http.Request, people *store.Store, state *syncState) {
+	uid, ok := cardUID(r.URL.Path)
+	index, found := people.Find(uid)
+	if !ok || !found {
+		http.NotFound(w, r)
+		return
+	}
+	etag := state.Cards[uid].ETag
+	w.Header().Set("ETag", etag)
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	card := Encode(people.People[index])
+	w.Header().Set("Content-Type", "text/vcard; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(card)))
+	if r.Method == "GET" {
+		io.WriteString(w, card)
+	}
+}
+
+// put creates or replaces a person from a vCard
+func (server *Server) put(w http.ResponseWriter, r *http.Request, people *store.Store, state *syncState) {
+	uid, ok := cardUID(r.URL.Path)
+	if !ok {
+		http.Error(w, "cards live at "+bookPath+"<uid>.vcf", http.StatusForbidden)
+		return
+	}
+	index, exists := people.Find(uid)
+	etag := ""
+	var existing *structs.Person
+	if exists {
+		etag = state.Cards[uid].ETag
+		existing = &people.People[index]
+	}
+	if !preconditionsHold(r, etag, exists) {
+		http.Error(w, "the card changed on the server", http.StatusPreconditionFailed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	person, err := Decode(string(body), existing)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	if person.UID == "" {
+		person.UID = uid
+	}
+	if person.UID != uid {
+		http.Error(w, fmt.Sprintf("UID %q does not match the URL (%s)", person.UID, cardHref(person.UID)), http.StatusBadRequest)
+		return
+	}
+
+	people.Put(person)
+	if err := server.commit(people, state); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", state.Cards[uid].ETag)
+	if exists {
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// delete removes a person
+func (server *Server) delete(w http.ResponseWriter, r *http.Request, people *store.Store, state *syncState) {
+	uid, ok := cardUID(r.URL.Path)
+	_, exists := people.Find(uid)
+	if !ok || !exists {
+		http.NotFound(w, r)
+		return
+	}
+	if !preconditionsHold(r, state.Cards[uid].ETag, true) {
+		http.Error(w, "the card changed on the server", http.StatusPreconditionFailed)
+		return
+	}
+	people.Remove(uid)
+	if err := server.commit(people, state); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// preconditionsHold checks If-Match and If-None-Match for a write
+// This is what stops a phone from overwriting a change it has not seen yet
+func preconditionsHold(r *http.Request, etag string, exists bool) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if !exists || !matchesETag(ifMatch, etag) {
+			return false
+		}
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && exists && matchesETag(ifNoneMatch, etag) {
+		return false
+	}
+	return true
+}
+
+// matchesETag reports whether a header like `"a1", "b2"` or `*` matches etag
+func matchesETag(header string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// =====================
+// PROPFIND
+// =====================
+
+// xmlName is any element, used to read the list inside <prop>
+type xmlName struct {
+	XMLName xml.Name
+}
+
+// propList is the <prop> element of a request
+type propList struct {
+	Names []xmlName `xml:",any"`
+}
+
+// names returns the requested properties
+func (list propList) names() []xml.Name {
+	names := []xml.Name{}
+	for _, name := range list.Names {
+		names = append(names, name.XMLName)
+	}
+	return names
+}
+
+type propfindBody struct {
+	AllProp *struct{} `xml:"allprop"`
+	Prop    *propList `xml:"prop"`
+}
+
+// propfind lists properties of a resource and, with Depth: 1, of its children
+func (server *Server) propfind(w http.ResponseWriter, r *http.Request, people *store.Store, state *syncState) {
+	var body propfindBody
+	if err := readXML(r, &body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var requested []xml.Name // nil = every property (allprop or an empty body)
+	if body.AllProp == nil && body.Prop != nil {
+		requested = body.Prop.names()
+	}
+
+	path := r.URL.EscapedPath()
+	if !strings.HasSuffix(path, "/") && !strings.HasSuffix(path, ".vcf") {
+		path += "/"
+	}
+
+	hrefs := []string{path}
+	if r.Header.Get("Depth") != "0" {
+		switch path {
+		case "/":
+			hrefs = append(hrefs, principalPath, homePath)
+		case homePath:
+			hrefs = append(hrefs, bookPath)
+		case bookPath:
+			for _, person := range people.People {
+				hrefs = append(hrefs, cardHref(person.UID))
+			}
+		}
+	}
+
+	responses := []response{}
+	for _, href := range hrefs {
+		props, ok := server.properties(href, people, state)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		responses = append(responses, propResponse(href, props, requested))
+	}
+	writeMultistatus(w, responses, "")
+}
+
+// properties returns every property of a resource, as XML
+// address-data is only sent when asked for (see propResponse)
+func (server *Server) properties(href string, people *store.Store, state *syncState) (map[xml.Name]string, bool) {
+	dav := func(local string) xml.Name { return xml.Name{Space: nsDAV, Local: local} }
+	card := func(local string) xml.Name { return xml.Name{Space: nsCardDAV, Local: local} }
+	principal := "<d:href>" + principalPath + "</d:href>"
+
+	switch href {
+	case "/":
+		return map[xml.Name]string{
+			dav("resourcetype"):           "<d:collection/>",
+			dav("current-user-principal"): principal,
+		}, true
+	case principalPath:
+		return map[xml.Name]string{
+			dav("resourcetype"):           "<d:principal/>",
+			dav("displayname"):            "People",
+			dav("current-user-principal"): principal,
+			dav("principal-URL"):          principal,
+			card("addressbook-home-set"):  "<d:href>" + homePath + "</d:href>",
+		}, true
+	case homePath:
+		return map[xml.Name]string{
+			dav("resourcetype"):           "<d:collection/>",
+			dav("current-user-principal"): principal,
+		}, true
+	case bookPath:
+		return map[xml.Name]string{
+			dav("resourcetype"):                     "<d:collection/><card:addressbook/>",
+			dav("displayname"):                      "People",
+			dav("current-user-principal"):           principal,
+			dav("sync-token"):                       escapeXML(state.token()),
+			dav("supported-report-set"):             supportedReports(),
+			xml.Name{Space: nsCS, Local: "getctag"}: escapeXML(state.token()),
+			card("addressbook-description"):         "People from " + escapeXML(people.Path()),
+			card("supported-address-data"):          `<card:address-data-type content-type="text/vcard" version="3.0"/>`,
+		}, true
+	}
+
+	uid, ok := cardUID(unescapePath(href))
+	if !ok {
+		return nil, false
+	}
+	index, found := people.Find(uid)
+	if !found {
+		return nil, false
+	}
+	return map[xml.Name]string{
+		dav("resourcetype"):   "",
+		dav("getetag"):        escapeXML(state.Cards[uid].ETag),
+		dav("getcontenttype"): "text/vcard; charset=utf-8",
+		card("address-data"):  escapeXML(Encode(people.People[index])),
+	}, true
+}
+
+// supportedReports lists the REPORTs the address book answers
+func supportedReports() string {
+	reports := ""
+	for _, name := range []string{"card:addressbook-query", "card:addressbook-multiget", "d:sync-collection"} {
+		reports += "<d:supported-report><d:report><" + name + "/></d:report></d:supported-report>"
+	}
+	return reports
+}
+
+// =====================
+// REPORT
+// =====================
+
+type reportBody struct {
+	XMLName   xml.Name
+	AllProp   *struct{} `xml:"allprop"`
+	Prop      *propList `xml:"prop"`
+	Hrefs     []string  `xml:"href"`       // addressbook-multiget
+	Filter    filter    `xml:"filter"`     // addressbook-query
+	SyncToken string    `xml:"sync-token"` // sync-collection
+}
+
+// report answers the three address book REPORTs
+func (server *Server) report(w http.ResponseWriter, r *http.Request, people *store.Store, state *syncState) {
+	if r.URL.Path != bookPath && r.URL.Path+"/" != bookPath {
+		http.Error(w, "reports are only supported on "+bookPath, http.StatusForbidden)
+		return
+	}
+	var body reportBody
+	if err := readXML(r, &body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var requested []xml.Name
+	if body.AllProp == nil && body.Prop != nil {
+		requested = body.Prop.names()
+	}
+
+	responses := []response{}
+	syncToken := ""
+	switch body.XMLName {
+	case xml.Name{Space: nsCardDAV, Local: "addressbook-query"}:
+		for _, person := range people.People {
+			if body.Filter.matches(person) {
+				href := cardHref(person.UID)
+				props, _ := server.properties(href, people, state)
+				responses = append(responses, propResponse(href, props, requested))
+			}
+		}
+
+	case xml.Name{Space: nsCardDAV, Local: "addressbook-multiget"}:
+		for _, href := range body.Hrefs {
+			href = strings.TrimSpace(href)
+			if parsed, err := url.Parse(href); err == nil {
+				href = parsed.EscapedPath() // Clients may send full URLs
+			}
+			props, ok := server.properties(href, people, state)
+			if !ok {
+				responses = append(responses, response{href: href, status: http.StatusNotFound})
+				continue
+			}
+			responses = append(responses, propResponse(href, props, requested))
+		}
+
+	case xml.Name{Space: nsDAV, Local: "sync-collection"}:
+		uids, err := state.since(strings.TrimSpace(body.SyncToken))
+		if errors.Is(err, errInvalidToken) {
+			// The client has to throw its copy away and sync from scratch
+			w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, xml.Header+`<d:error xmlns:d="DAV:"><d:valid-sync-token/></d:error>`)
+			return
+		}
+		for _, uid := range uids {
+			href := cardHref(uid)
+			if state.Cards[uid].Deleted {
+				responses = append(responses, response{href: href, status: http.StatusNotFound})
+				continue
+			}
+			props, _ := server.properties(href, people, state)
+			responses = append(responses, propResponse(href, props, requested))
+		}
+		syncToken = state.token()
+
+	default:
+		http.Error(w, "unsupported report "+body.XMLName.Local, http.StatusForbidden)
+		return
+	}
+	writeMultistatus(w, responses, syncToken)
+}
+
+// =====================
+// ADDRESSBOOK-QUERY FILTERS
+// =====================
+
+// filter is <card:filter>: prop-filters combined with anyof (default) or allof
+type filter struct {
+	Test        string       `xml:"test,attr"`
+	PropFilters []propFilter `xml:"prop-filter"`
+}
+
+type propFilter struct {
+	Name         string      `xml:"name,attr"`
+	Test         string      `xml:"test,attr"`
+	IsNotDefined *struct{}   `xml:"is-not-defined"`
+	TextMatches  []textMatch `xml:"text-match"`
+}
+
+type textMatch struct {
+	Collation string `xml:"collation,attr"`  // i;unicode-casemap (default) ignores case, i;octet does not
+	MatchType string `xml:"match-type,attr"` // equals, contains (default), starts-with, ends-with
+	Negate    string `xml:"negate-condition,attr"`
+	Text      string `xml:",chardata"`
+}
+
+// matches reports whether the person's vCard passes the filter (no filters = everyone)
+func (f filter) matches(person structs.Person) bool {
+	if len(f.PropFilters) == 0 {
+		return true
+	}
+	properties, _ := parse(Encode(person))
+	for _, pf := range f.PropFilters {
+		ok := pf.matches(properties)
+		if f.Test == "allof" && !ok {
+			return false
+		}
+		if f.Test != "allof" && ok {
+			return true
+		}
+	}
+	return f.Test == "allof"
+}
+
+// matches checks one property filter against the properties of a card
+func (pf propFilter) matches(properties []property) bool {
+	values := []string{}
+	for _, p := range properties {
+		if strings.EqualFold(p.name, pf.Name) {
+			values = append(values, unescape(p.value))
+		}
+	}
+	if pf.IsNotDefined != nil {
+		return len(values) == 0
+	}
+	if len(values) == 0 {
+		return false
+	}
+	if len(pf.TextMatches) == 0 {
+		return true // The property only has to exist
+	}
+	for _, tm := range pf.TextMatches {
+		ok := false
+		for _, value := range values {
+			if tm.matches(value) {
+				ok = true
+				break
+			}
+		}
+		if pf.Test == "allof" && !ok {
+			return false
+		}
+		if pf.Test != "allof" && ok {
+			return true
+		}
+	}
+	return pf.Test == "allof"
+}
+
+// matches compares one value with the text of a text-match
+func (tm textMatch) matches(value string) bool {
+	text := tm.Text
+	if tm.Collation != "i;octet" {
+		text, value = strings.ToLower(text), strings.ToLower(value)
+	}
+	var ok bool
+	switch tm.MatchType {
+	case "equals":
+		ok = value == text
+	case "starts-with":
+		ok = strings.HasPrefix(value, text)
+	case "ends-with":
+		ok = strings.HasSuffix(value, text)
+	default:
+		ok = strings.Contains(value, text)
+	}
+	return ok != (tm.Negate == "yes")
+}
+
+// =====================
+// MULTISTATUS RESPONSES
+// =====================
+
+// response is one <d:response> of a multistatus
+// With a status (e.g., 404) it has no properties
+type response struct {
+	href    string
+	status  int
+	found   []prop
+	missing []xml.Name
+}
+
+// prop is a property with its value, already written as XML
+type prop struct {
+	name  xml.Name
+	value string
+}
+
+// propResponse picks the requested properties of a resource
+// requested == nil means all of them (except address-data, which is big)
+func propResponse(href string, props map[xml.Name]string, requested []xml.Name) response {
+	resp := response{href: href}
+	if requested == nil {
+		for name, value := range props {
+			if name.Local != "address-data" {
+				resp.found = append(resp.found, prop{name, value})
+			}
+		}
+		return resp
+	}
+	for _, name := range requested {
+		if value, ok := props[name]; ok {
+			resp.found = append(resp.found, prop{name, value})
+		} else {
+			resp.missing = append(resp.missing, name)
+		}
+	}
+	return resp
+}
+
+// writeMultistatus sends a 207 Multi-Status with the responses
+// syncToken is only set for sync-collection
+func writeMultistatus(w http.ResponseWriter, responses []response, syncToken string) {
+	var out strings.Builder
+	out.WriteString(xml.Header)
+	out.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:card="` + nsCardDAV + `" xmlns:cs="` + nsCS + `">`)
+	for _, resp := range responses {
+		out.WriteString("<d:response><d:href>" + escapeXML(resp.href) + "</d:href>")
+		if resp.status != 0 {
+			out.WriteString("<d:status>" + statusLine(resp.status) + "</d:status>")
+		}
+		if len(resp.found) > 0 {
+			out.WriteString("<d:propstat><d:prop>")
+			for _, p := range resp.found {
+				open, name := element(p.name)
+				if p.value == "" {
+					out.WriteString("<" + open + "/>")
+				} else {
+					out.WriteString("<" + open + ">" + p.value + "</" + name + ">")
+				}
+			}
+			out.WriteString("</d:prop><d:status>" + statusLine(http.StatusOK) + "</d:status></d:propstat>")
+		}
+		if len(resp.missing) > 0 {
+			out.WriteString("<d:propstat><d:prop>")
+			for _, missing := range resp.missing {
+				open, _ := element(missing)
+				out.WriteString("<" + open + "/>")
+			}
+			out.WriteString("</d:prop><d:status>" + statusLine(http.StatusNotFound) + "</d:status></d:propstat>")
+		}
+		out.WriteString("</d:response>")
+	}
+	if syncToken != "" {
+		out.WriteString("<d:sync-token>" + escapeXML(syncToken) + "</d:sync-token>")
+	}
+	out.WriteString("</d:multistatus>")
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	io.WriteString(w, out.String())
+}
+
+// element returns the opening tag (with xmlns for unknown namespaces) and the name
+func element(name xml.Name) (string, string) {
+	if prefix, ok := prefixes[name.Space]; ok {
+		return prefix + ":" + name.Local, prefix + ":" + name.Local
+	}
+	return "x:" + name.Local + ` xmlns:x="` + escapeXML(name.Space) + `"`, "x:" + name.Local
+}
+
+// statusLine is the status as WebDAV writes it: "HTTP/1.1 404 Not Found"
+func statusLine(code int) string {
+	return "HTTP/1.1 " + strconv.Itoa(code) + " " + http.StatusText(code)
+}
+
+// escapeXML escapes text for use inside an element or attribute
+func escapeXML(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
+
+// unescapePath decodes %XX in an href, so it matches r.URL.Path
+func unescapePath(href string) string {
+	if path, err := url.PathUnescape(href); err == nil {
+		return path
+	}
+	return href
+}
+
+// readXML decodes a request body; an empty body leaves v as it is
+func readXML(r *http.Request, v any) error {
+	data, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return nil
+	}
+	return xml.Unmarshal(data, v)
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// carddav.NewServer("people.json")        -> http.Handler for the address book
+// http.ListenAndServe(addr, server)       -> serve it
+// carddav.Encode(person)                  -> vCard 3.0 text
+// carddav.Decode(card, existing)          -> person from a vCard
+// PROPFIND Depth: 1 /addressbooks/people/ -> every card with its ETag
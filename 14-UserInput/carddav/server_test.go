@@ -0,0 +1,409 @@
+package carddav
+
+import (
+	"14-UserInput/store"
+	"14-UserInput/structs"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// =====================
+// TEST SERVER AND HELPERS
+// =====================
+
+// newTestServer serves a fresh store with three people in a temporary folder
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "people.json")
+	people, err := store.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	people.Add(structs.Person{UID: "sara", Name: "Sara de Vries", Age: 34, Information: map[string]string{"email": "sara@example.com"}})
+	people.Add(structs.Person{UID: "piet", Name: "Piet Jansen", Age: 51, Information: map[string]string{"phone": "+31 20 123 4567"}})
+	people.Add(structs.Person{UID: "ann", Name: "Ann Lee", Age: 28})
+	if err := people.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(NewServer(path))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// result is a response with its body read
+type result struct {
+	status int
+	header http.Header
+	body   string
+}
+
+// send makes one request; headers are name, value pairs
+func send(t *testing.T, server *httptest.Server, method string, path string, body string, headers ...string) result {
+	t.Helper()
+	request, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result{status: response.StatusCode, header: response.Header, body: string(data)}
+}
+
+// multistatus is a 207 response as a client reads it
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Status   string `xml:"DAV: status"`
+		Propstat []struct {
+			Prop struct {
+				ETag         string `xml:"DAV: getetag"`
+				SyncToken    string `xml:"DAV: sync-token"`
+				ResourceType struct {
+					Inner string `xml:",innerxml"`
+				} `xml:"DAV: resourcetype"`
+				AddressData string `xml:"urn:ietf:params:xml:ns:carddav address-data"`
+			} `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+	SyncToken string `xml:"DAV: sync-token"`
+}
+
+// readMultistatus checks for a 207 and decodes it
+func readMultistatus(t *testing.T, res result) multistatus {
+	t.Helper()
+	if res.status != http.StatusMultiStatus {
+		t.Fatalf("status %d, want 207: %s", res.status, res.body)
+	}
+	var ms multistatus
+	if err := xml.Unmarshal([]byte(res.body), &ms); err != nil {
+		t.Fatalf("%v in %s", err, res.body)
+	}
+	return ms
+}
+
+// hrefs lists the href of every response
+func (ms multistatus) hrefs() []string {
+	hrefs := []string{}
+	for _, resp := range ms.Responses {
+		hrefs = append(hrefs, resp.Href)
+	}
+	return hrefs
+}
+
+// etag returns the ETag of a card in a PROPFIND or REPORT response
+func (ms multistatus) etag(href string) string {
+	for _, resp := range ms.Responses {
+		if resp.Href == href {
+			for _, propstat := range resp.Propstat {
+				if propstat.Prop.ETag != "" {
+					return propstat.Prop.ETag
+				}
+			}
+		}
+	}
+	return ""
+}
+
+const (
+	propfindETag = `<d:propfind xmlns:d="DAV:"><d:prop><d:getetag/><d:resourcetype/></d:prop></d:propfind>`
+	syncReport   = `<d:sync-collection xmlns:d="DAV:"><d:sync-token>%s</d:sync-token><d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`
+	newCard      = "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:mo\r\nFN:Mo Haddad\r\nEMAIL:mo@example.com\r\nEND:VCARD\r\n"
+)
+
+// =====================
+// PROPFIND
+// =====================
+
+func TestPropfindDepth(t *testing.T) {
+	server := newTestServer(t)
+	tests := []struct {
+		name  string
+		path  string
+		depth string
+		want  []string
+	}{
+		{"address book only", bookPath, "0", []string{bookPath}},
+		{"address book and cards", bookPath, "1", []string{bookPath, cardHref("sara"), cardHref("piet"), cardHref("ann")}},
+		{"home and address book", homePath, "1", []string{homePath, bookPath}},
+		{"one card", cardHref("piet"), "0", []string{cardHref("piet")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ms := readMultistatus(t, send(t, server, "PROPFIND", test.path, propfindETag, "Depth", test.depth))
+			if got := ms.hrefs(); !slices.Equal(got, test.want) {
+				t.Fatalf("hrefs %q, want %q", got, test.want)
+			}
+			for _, href := range test.want {
+				if strings.HasSuffix(href, ".vcf") && ms.etag(href) == "" {
+					t.Errorf("%s has no ETag", href)
+				}
+			}
+		})
+	}
+
+	ms := readMultistatus(t, send(t, server, "PROPFIND", bookPath, propfindETag, "Depth", "0"))
+	if !strings.Contains(ms.Responses[0].Propstat[0].Prop.ResourceType.Inner, "addressbook") {
+		t.Errorf("address book resourcetype %q", ms.Responses[0].Propstat[0].Prop.ResourceType.Inner)
+	}
+
+	if res := send(t, server, "PROPFIND", cardHref("nobody"), propfindETag, "Depth", "0"); res.status != http.StatusNotFound {
+		t.Errorf("PROPFIND of an unknown card: status %d, want 404", res.status)
+	}
+}
+
+// =====================
+// REPORT: ADDRESSBOOK-QUERY AND MULTIGET
+// =====================
+
+func TestAddressbookQuery(t *testing.T) {
+	server := newTestServer(t)
+	query := func(test string, filters string) string {
+		return `<card:addressbook-query xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">` +
+			`<d:prop><d:getetag/></d:prop><card:filter test="` + test + `">` + filters + `</card:filter></card:addressbook-query>`
+	}
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"no filter", query("", ""), []string{"sara", "piet", "ann"}},
+		{"contains ignores case", query("", `<card:prop-filter name="FN"><card:text-match>JANSEN</card:text-match></card:prop-filter>`), []string{"piet"}},
+		{"octet is case sensitive", query("", `<card:prop-filter name="FN"><card:text-match collation="i;octet">JANSEN</card:text-match></card:prop-filter>`), []string{}},
+		{"starts-with", query("", `<card:prop-filter name="FN"><card:text-match match-type="starts-with">sa</card:text-match></card:prop-filter>`), []string{"sara"}},
+		{"equals", query("", `<card:prop-filter name="FN"><card:text-match match-type="equals">Ann Lee</card:text-match></card:prop-filter>`), []string{"ann"}},
+		{"negated", query("", `<card:prop-filter name="FN"><card:text-match negate-condition="yes">jansen</card:text-match></card:prop-filter>`), []string{"sara", "ann"}},
+		{"property exists", query("", `<card:prop-filter name="EMAIL"/>`), []string{"sara"}},
+		{"is-not-defined", query("", `<card:prop-filter name="EMAIL"><card:is-not-defined/></card:prop-filter>`), []string{"piet", "ann"}},
+		{"anyof", query("anyof", `<card:prop-filter name="EMAIL"/><card:prop-filter name="TEL"/>`), []string{"sara", "piet"}},
+		{"allof", query("allof", `<card:prop-filter name="EMAIL"/><card:prop-filter name="TEL"/>`), []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ms := readMultistatus(t, send(t, server, "REPORT", bookPath, test.body, "Depth", "1"))
+			want := []string{}
+			for _, uid := range test.want {
+				want = append(want, cardHref(uid))
+			}
+			if got := ms.hrefs(); !slices.Equal(got, want) {
+				t.Fatalf("hrefs %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestAddressbookMultiget(t *testing.T) {
+	server := newTestServer(t)
+	body := `<card:addressbook-multiget xmlns:d="DAV:" xmlns:card="urn:ietf:params:xml:ns:carddav">` +
+		`<d:prop><d:getetag/><card:address-data/></d:prop>` +
+		`<d:href>` + cardHref("piet") + `</d:href><d:href>` + cardHref("gone") + `</d:href><d:href>` + server.URL + cardHref("sara") + `</d:href>` +
+		`</card:addressbook-multiget>`
+	ms := readMultistatus(t, send(t, server, "REPORT", bookPath, body, "Depth", "1"))
+
+	want := []string{cardHref("piet"), cardHref("gone"), cardHref("sara")}
+	if got := ms.hrefs(); !slices.Equal(got, want) {
+		t.Fatalf("hrefs %q, want %q", got, want)
+	}
+	if !strings.Contains(ms.Responses[0].Propstat[0].Prop.AddressData, "FN:Piet Jansen") {
+		t.Errorf("address-data of piet: %q", ms.Responses[0].Propstat[0].Prop.AddressData)
+	}
+	if !strings.Contains(ms.Responses[1].Status, "404") || len(ms.Responses[1].Propstat) != 0 {
+		t.Errorf("unknown href: status %q with %d propstats, want a bare 404", ms.Responses[1].Status, len(ms.Responses[1].Propstat))
+	}
+	if ms.etag(cardHref("sara")) == "" {
+		t.Error("a full URL in href was not found")
+	}
+}
+
+// =====================
+// PUT AND DELETE
+// =====================
+
+func TestPutCreateAndReplace(t *testing.T) {
+	server := newTestServer(t)
+	href := cardHref("mo")
+
+	// If-None-Match: * creates only when the card does not exist yet
+	created := send(t, server, "PUT", href, newCard, "If-None-Match", "*", "Content-Type", "text/vcard")
+	if created.status != http.StatusCreated || created.header.Get("ETag") == "" {
+		t.Fatalf("create: status %d, ETag %q", created.status, created.header.Get("ETag"))
+	}
+	if res := send(t, server, "PUT", href, newCard, "If-None-Match", "*"); res.status != http.StatusPreconditionFailed {
+		t.Errorf("second create with If-None-Match *: status %d, want 412", res.status)
+	}
+	got := send(t, server, "GET", href, "")
+	if got.status != http.StatusOK || !strings.Contains(got.body, "FN:Mo Haddad") || got.header.Get("ETag") != created.header.Get("ETag") {
+		t.Fatalf("GET after create: status %d, ETag %q, body %q", got.status, got.header.Get("ETag"), got.body)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"stale If-Match", "If-Match", `"not-the-etag"`, http.StatusPreconditionFailed},
+		{"If-None-Match with the current ETag", "If-None-Match", created.header.Get("ETag"), http.StatusPreconditionFailed},
+		{"current If-Match", "If-Match", created.header.Get("ETag"), http.StatusNoContent},
+	}
+	changed := strings.Replace(newCard, "Mo Haddad", "Mohammed Haddad", 1)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if res := send(t, server, "PUT", href, changed, test.header, test.value); res.status != test.want {
+				t.Fatalf("status %d, want %d: %s", res.status, test.want, res.body)
+			}
+		})
+	}
+
+	replaced := send(t, server, "GET", href, "")
+	if !strings.Contains(replaced.body, "FN:Mohammed Haddad") || replaced.header.Get("ETag") == created.header.Get("ETag") {
+		t.Errorf("after replace: ETag %q (was %q), body %q", replaced.header.Get("ETag"), created.header.Get("ETag"), replaced.body)
+	}
+	if res := send(t, server, "PUT", href, changed, "If-Match", "*"); res.status != http.StatusNoContent {
+		t.Errorf("If-Match * on an existing card: status %d, want 204", res.status)
+	}
+	if res := send(t, server, "PUT", cardHref("new"), newCard, "If-Match", "*"); res.status != http.StatusPreconditionFailed {
+		t.Errorf("If-Match * on a missing card: status %d, want 412", res.status)
+	}
+	if res := send(t, server, "PUT", cardHref("other"), newCard); res.status != http.StatusBadRequest {
+		t.Errorf("UID that does not match the URL: status %d, want 400", res.status)
+	}
+}
+
+// A card with two emails and two phones comes back with the same properties and TYPEs,
+// and sending that back (as a phone does on the next sync) changes nothing
+func TestPutRoundTrip(t *testing.T) {
+	server := newTestServer(t)
+	href := cardHref("kim")
+	card := "BEGIN:VCARD\r\nVERSION:3.0\r\nUID:kim\r\nFN:Kim Bakker\r\n" +
+		"EMAIL;TYPE=INTERNET,HOME:kim@example.com\r\nEMAIL;TYPE=INTERNET;TYPE=WORK:kim@work.example\r\n" +
+		"TEL;TYPE=CELL:+31 6 1234 5678\r\nTEL;WORK;VOICE:+31 20 765 4321\r\nTEL:+31 10 555 1234\r\nTEL:+31 10 555 9999\r\n" +
+		"END:VCARD\r\n"
+	if res := send(t, server, "PUT", href, card); res.status != http.StatusCreated {
+		t.Fatalf("PUT: status %d: %s", res.status, res.body)
+	}
+
+	first := send(t, server, "GET", href, "")
+	want := []string{
+		"EMAIL;TYPE=INTERNET,HOME:kim@example.com",
+		"EMAIL;TYPE=INTERNET,WORK:kim@work.example",
+		"TEL;TYPE=CELL:+31 6 1234 5678",
+		"TEL;TYPE=WORK:+31 20 765 4321",
+		"TEL:+31 10 555 1234",
+		"TEL:+31 10 555 9999", // Stored as "Phone 2"
+	}
+	for _, line := range want {
+		if !strings.Contains(first.body, line+"\r\n") {
+			t.Errorf("GET is missing %q:\n%s", line, first.body)
+		}
+	}
+	if strings.Contains(first.body, "X-INFO") {
+		t.Errorf("emails or phones came back as X-INFO:\n%s", first.body)
+	}
+
+	if res := send(t, server, "PUT", href, first.body, "If-Match", first.header.Get("ETag")); res.status != http.StatusNoContent {
+		t.Fatalf("PUT of the card as served: status %d: %s", res.status, res.body)
+	}
+	second := send(t, server, "GET", href, "")
+	if second.body != first.body || second.header.Get("ETag") != first.header.Get("ETag") {
+		t.Errorf("the card changed on the round trip:\nfirst  %q\nsecond %q", first.body, second.body)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	server := newTestServer(t)
+	etag := send(t, server, "GET", cardHref("ann"), "").header.Get("ETag")
+
+	if res := send(t, server, "DELETE", cardHref("ann"), "", "If-Match", `"stale"`); res.status != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with a stale If-Match: status %d, want 412", res.status)
+	}
+	if res := send(t, server, "DELETE", cardHref("ann"), "", "If-Match", etag); res.status != http.StatusNoContent {
+		t.Fatalf("DELETE: status %d, want 204", res.status)
+	}
+	if res := send(t, server, "GET", cardHref("ann"), ""); res.status != http.StatusNotFound {
+		t.Errorf("GET after DELETE: status %d, want 404", res.status)
+	}
+	if res := send(t, server, "DELETE", cardHref("ann"), ""); res.status != http.StatusNotFound {
+		t.Errorf("second DELETE: status %d, want 404", res.status)
+	}
+}
+
+// =====================
+// SYNC-COLLECTION
+// =====================
+
+func TestSyncCollection(t *testing.T) {
+	server := newTestServer(t)
+	sync := func(token string) multistatus {
+		t.Helper()
+		body := strings.Replace(syncReport, "%s", token, 1)
+		return readMultistatus(t, send(t, server, "REPORT", bookPath, body))
+	}
+
+	// First sync: every card, and a token to continue from
+	first := sync("")
+	if got, want := first.hrefs(), []string{cardHref("ann"), cardHref("piet"), cardHref("sara")}; !slices.Equal(got, want) {
+		t.Fatalf("first sync %q, want %q", got, want)
+	}
+	if first.SyncToken == "" {
+		t.Fatal("first sync has no sync-token")
+	}
+
+	// Nothing changed: nothing to report, same token
+	unchanged := sync(first.SyncToken)
+	if len(unchanged.Responses) != 0 || unchanged.SyncToken != first.SyncToken {
+		t.Fatalf("unchanged sync: %q, token %q (was %q)", unchanged.hrefs(), unchanged.SyncToken, first.SyncToken)
+	}
+
+	// A change and a delete: only those two come back, the deleted one as a 404
+	changed := Encode(structs.Person{UID: "piet", Name: "Piet Jansen", Age: 52})
+	if res := send(t, server, "PUT", cardHref("piet"), changed, "If-Match", first.etag(cardHref("piet"))); res.status != http.StatusNoContent {
+		t.Fatalf("PUT piet: status %d: %s", res.status, res.body)
+	}
+	if res := send(t, server, "DELETE", cardHref("sara"), ""); res.status != http.StatusNoContent {
+		t.Fatalf("DELETE sara: status %d", res.status)
+	}
+	after := sync(first.SyncToken)
+	if got, want := after.hrefs(), []string{cardHref("piet"), cardHref("sara")}; !slices.Equal(got, want) {
+		t.Fatalf("sync after changes %q, want %q", got, want)
+	}
+	if after.etag(cardHref("piet")) == first.etag(cardHref("piet")) {
+		t.Error("changed card kept its old ETag")
+	}
+	if !strings.Contains(after.Responses[1].Status, "404") {
+		t.Errorf("deleted card status %q, want 404", after.Responses[1].Status)
+	}
+	if after.SyncToken == first.SyncToken {
+		t.Error("sync token did not move after changes")
+	}
+
+	// A fresh sync does not report deletions
+	if got, want := sync("").hrefs(), []string{cardHref("ann"), cardHref("piet")}; !slices.Equal(got, want) {
+		t.Errorf("fresh sync after delete %q, want %q", got, want)
+	}
+
+	// A token the server never gave out: 403 with DAV:valid-sync-token
+	for _, token := range []string{"urn:people-sync:999", "nonsense"} {
+		res := send(t, server, "REPORT", bookPath, strings.Replace(syncReport, "%s", token, 1))
+		if res.status != http.StatusForbidden || !strings.Contains(res.body, "valid-sync-token") {
+			t.Errorf("token %q: status %d, body %q; want 403 with valid-sync-token", token, res.status, res.body)
+		}
+	}
+}
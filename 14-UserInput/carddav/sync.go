@@ -0,0 +1,143 @@
+package carddav
+
+import (
+	"14-UserInput/structs"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// =====================
+// SYNC STATE
+// =====================
+// Clients remember a sync token and later ask "what changed since then?"
+// The store only keeps the people, so the server keeps a small side file
+// (people.json.carddav) with a revision number per card, including deleted ones
+
+// tokenPrefix starts every sync token: "urn:people-sync:12"
+const tokenPrefix = "urn:people-sync:"
+
+// cardState is what the server remembers about one card
+type cardState struct {
+	ETag     string // Hash of the vCard; changes whenever the card changes
+	Revision int    // Revision the card last changed in
+	Deleted  bool   // Kept so sync-collection can report the deletion
+}
+
+// syncState is the side file: one revision counter for the whole address book
+type syncState struct {
+	path     string
+	Revision int
+	Cards    map[string]*cardState // By UID
+}
+
+// loadState reads the side file (a missing file gives an empty state)
+func loadState(path string) (*syncState, error) {
+	state := &syncState{path: path, Cards: map[string]*cardState{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Cards == nil {
+		state.Cards = map[string]*cardState{}
+	}
+	return state, nil
+}
+
+// save writes the side file the same way the store does (temporary file + rename)
+func (state *syncState) save() error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := state.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, state.path)
+}
+
+// refresh compares the state with the people in the store
+// Changes made outside the server (e.g., "go run . add") get a new revision here
+// It reports whether anything changed, so the caller knows to save
+func (state *syncState) refresh(people []structs.Person) bool {
+	changed := false
+	seen := map[string]bool{}
+	for _, person := range people {
+		seen[person.UID] = true
+		etag := etagOf(person)
+		card, ok := state.Cards[person.UID]
+		if ok && !card.Deleted && card.ETag == etag {
+			continue
+		}
+		if !changed {
+			state.Revision++
+			changed = true
+		}
+		state.Cards[person.UID] = &cardState{ETag: etag, Revision: state.Revision}
+	}
+	for uid, card := range state.Cards {
+		if seen[uid] || card.Deleted {
+			continue
+		}
+		if !changed {
+			state.Revision++
+			changed = true
+		}
+		card.Deleted = true
+		card.Revision = state.Revision
+	}
+	return changed
+}
+
+// token returns the current sync token
+func (state *syncState) token() string {
+	return tokenPrefix + strconv.Itoa(state.Revision)
+}
+
+// since lists the UIDs changed after a sync token, sorted
+// An empty token means "everything"; an unknown one is an error (the client starts over)
+func (state *syncState) since(token string) ([]string, error) {
+	revision := 0
+	if token != "" {
+		number, found := strings.CutPrefix(token, tokenPrefix)
+		parsed, err := strconv.Atoi(number)
+		if !found || err != nil || parsed < 0 || parsed > state.Revision {
+			return nil, errInvalidToken
+		}
+		revision = parsed
+	}
+
+	uids := []string{}
+	for uid, card := range state.Cards {
+		if card.Revision <= revision {
+			continue
+		}
+		if card.Deleted && token == "" {
+			continue // A first sync has nothing to delete
+		}
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	return uids, nil
+}
+
+// errInvalidToken is reported to the client as the DAV:valid-sync-token precondition
+var errInvalidToken = errors.New("carddav: invalid sync token")
+
+// etagOf hashes the vCard of a person; quoted, as HTTP wants ETags
+func etagOf(person structs.Person) string {
+	sum := sha256.Sum256([]byte(Encode(person)))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
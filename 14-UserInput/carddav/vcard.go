@@ -0,0 +1,403 @@
+package carddav
+
+import (
+	"14-UserInput/structs"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================
+// VCARD (RFC 2426, version 3.0)
+// =====================
+
+// property is one "NAME;PARAM=value:value" line of a vCard
+type property struct {
+	name   string            // Uppercase, e.g., "EMAIL"
+	params map[string]string // Uppercase keys, e.g., "TYPE"
+	value  string            // Still escaped
+}
+
+// infoProperty maps well-known Information keys (lowercase) to vCard properties
+// Other keys are kept as X-INFO;X-KEY=<key>, so nothing is lost
+var infoProperty = map[string]string{
+	"email":     "EMAIL",
+	"e-mail":    "EMAIL",
+	"mail":      "EMAIL",
+	"phone":     "TEL",
+	"telephone": "TEL",
+	"tel":       "TEL",
+	"mobile":    "TEL",
+	"location":  "ADR",
+	"city":      "ADR",
+}
+
+// defaultKey is the Information key used for a property coming from a client
+// Its TYPE values go in brackets and a second one gets a number: "Phone (work) 2"
+var defaultKey = map[string]string{"EMAIL": "Email", "TEL": "Phone", "ADR": "Location"}
+
+// impliedTypes are TYPE values that every EMAIL or TEL has, so they are not kept in the key
+var impliedTypes = []string{"internet", "voice"}
+
+// infoKeyPattern splits an Information key into its name, TYPE values and number:
+// "Phone (work, cell) 2" -> "Phone", "work, cell"
+var infoKeyPattern = regexp.MustCompile(`^(.*?)(?:\s*\(([^()]*)\))?(?:\s+\d+)?$`)
+
+// propertyOf finds the vCard property an Information key is written as, with its TYPE values
+// "Email 2" is an EMAIL like "Email", "Phone (work)" a TEL;TYPE=WORK; other keys give ""
+func propertyOf(key string) (string, []string) {
+	parts := infoKeyPattern.FindStringSubmatch(strings.TrimSpace(key))
+	name, ok := infoProperty[strings.ToLower(parts[1])]
+	if !ok {
+		return "", nil
+	}
+	return name, typeList(parts[2])
+}
+
+// typeName is what a TYPE value can be: "home", "x-private"
+var typeName = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// typeList reads comma-separated TYPE values: lowercase, sorted, without the implied ones
+func typeList(text string) []string {
+	types := []string{}
+	for _, value := range strings.Split(text, ",") {
+		value = strings.ToLower(strings.TrimSpace(value))
+		if typeName.MatchString(value) && !slices.Contains(impliedTypes, value) && !slices.Contains(types, value) {
+			types = append(types, value)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// typeParam is the ";TYPE=..." of a property line ("" without types)
+func typeParam(types []string) string {
+	if len(types) == 0 {
+		return ""
+	}
+	return ";TYPE=" + strings.ToUpper(strings.Join(types, ","))
+}
+
+// Encode returns the person as a vCard, with CRLF line endings and folded lines
+func Encode(person structs.Person) string {
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"UID:" + escape(person.UID),
+		"FN:" + escape(person.Name),
+		"N:" + nameParts(person.Name),
+	}
+	if person.Age > 0 {
+		lines = append(lines, "X-AGE:"+strconv.Itoa(person.Age))
+	}
+	if person.Birthday != "" {
+		lines = append(lines, "BDAY:"+person.Birthday)
+	}
+
+	// Sorted keys, so the same person always gives the same vCard (and ETag)
+	keys := make([]string, 0, len(person.Information))
+	for key := range person.Information {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := escape(person.Information[key])
+		name, types := propertyOf(key)
+		switch name {
+		case "EMAIL":
+			lines = append(lines, "EMAIL"+typeParam(append([]string{"internet"}, types...))+":"+value)
+		case "TEL":
+			lines = append(lines, "TEL"+typeParam(types)+":"+value)
+		case "ADR":
+			lines = append(lines, "ADR"+typeParam(types)+":;;;"+value+";;;") // The location goes in the locality field
+		default:
+			lines = append(lines, "X-INFO;X-KEY="+paramValue(key)+":"+value)
+		}
+	}
+	lines = append(lines, "END:VCARD")
+
+	var card strings.Builder
+	for _, line := range lines {
+		card.WriteString(fold(line))
+	}
+	return card.String()
+}
+
+// Decode reads a vCard sent by a client into a person
+// existing is the stored version (nil for a new card); its Information keys are
+// reused so that "email" stays "email" instead of becoming "Email"
+// TYPE values are kept in the key ("Phone (work)"), so Encode can send them back
+func Decode(card string, existing *structs.Person) (structs.Person, error) {
+	properties, err := parse(card)
+	if err != nil {
+		return structs.Person{}, err
+	}
+
+	person := structs.Person{Information: map[string]string{}}
+	keyFor := map[string][]string{} // Property and types -> existing keys written as it, in order
+	signature := func(name string, types []string) string {
+		return name + ";" + strings.Join(types, ",")
+	}
+	if existing != nil {
+		keys := make([]string, 0, len(existing.Information))
+		for key := range existing.Information {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if name, types := propertyOf(key); name != "" {
+				keyFor[signature(name, types)] = append(keyFor[signature(name, types)], key)
+			}
+		}
+	}
+	addInfo := func(name string, types []string, value string) {
+		base := defaultKey[name]
+		if len(types) > 0 {
+			base += " (" + strings.Join(types, ", ") + ")"
+		}
+		key, found := base, keyFor[signature(name, types)]
+		if len(found) > 0 {
+			key, keyFor[signature(name, types)] = found[0], found[1:]
+		}
+		for n := 2; person.Information[key] != ""; n++ {
+			key = fmt.Sprintf("%s %d", base, n) // A second email becomes "Email 2"
+		}
+		person.Information[key] = value
+	}
+
+	structuredName := ""
+	for _, p := range properties {
+		switch p.name {
+		case "UID":
+			person.UID = unescape(p.value)
+		case "FN":
+			person.Name = unescape(p.value)
+		case "N":
+			structuredName = p.value
+		case "X-AGE":
+			person.Age, _ = strconv.Atoi(strings.TrimSpace(p.value))
+		case "BDAY":
+			person.Birthday = birthday(p.value)
+		case "EMAIL", "TEL":
+			addInfo(p.name, typeList(p.params["TYPE"]), unescape(p.value))
+		case "ADR":
+			// post-office-box;extended;street;locality;region;postal-code;country
+			fields := splitUnescaped(p.value, ';')
+			parts := []string{}
+			for _, index := range []int{2, 3, 6} {
+				if index < len(fields) && fields[index] != "" {
+					parts = append(parts, fields[index])
+				}
+			}
+			if len(parts) > 0 {
+				addInfo("ADR", typeList(p.params["TYPE"]), strings.Join(parts, ", "))
+			}
+		case "X-INFO":
+			if key := p.params["X-KEY"]; key != "" {
+				person.Information[key] = unescape(p.value)
+			}
+		}
+	}
+
+	if person.Name == "" && structuredName != "" {
+		// N is "family;given;additional;prefix;suffix"
+		fields := splitUnescaped(structuredName, ';')
+		if len(fields) > 1 {
+			person.Name = strings.TrimSpace(fields[1] + " " + fields[0])
+		} else {
+			person.Name = fields[0]
+		}
+	}
+	if person.Name == "" {
+		return structs.Person{}, errors.New("vcard: missing FN (name)")
+	}
+	if existing != nil && person.Age == 0 {
+		person.Age = existing.Age // Clients that do not know X-AGE drop it
+	}
+	decoded := structs.NewPerson(person.Name, person.Age, person.Information)
+	decoded.UID = person.UID
+	decoded.Birthday = person.Birthday
+	if existing != nil {
+		decoded.Attachments = existing.Attachments // Not part of the vCard, so keep them
+	}
+	return decoded, nil
+}
+
+// birthday reads BDAY as "YYYY-MM-DD"; clients also send "19900417" or "1990-04-17T00:00:00Z"
+// Dates without a year ("--0417") cannot be stored and are dropped
+func birthday(value string) string {
+	value, _, _ = strings.Cut(strings.TrimSpace(value), "T")
+	for _, layout := range []string{structs.DateLayout, "20060102"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date.Format(structs.DateLayout)
+		}
+	}
+	return ""
+}
+
+// parse unfolds the lines of a vCard and splits them into properties
+func parse(card string) ([]property, error) {
+	// Unfold: a line starting with a space or tab continues the previous one
+	card = strings.ReplaceAll(card, "\r\n", "\n")
+	card = strings.ReplaceAll(card, "\n ", "")
+	card = strings.ReplaceAll(card, "\n\t", "")
+
+	properties := []property{}
+	began, ended := false, false
+	for number, line := range strings.Split(card, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		nameAndParams, value, found := cutUnquoted(line, ':')
+		if !found {
+			return nil, fmt.Errorf("vcard: line %d has no ':'", number+1)
+		}
+		parts := splitQuoted(nameAndParams)
+		p := property{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: value}
+		if dot := strings.LastIndexByte(p.name, '.'); dot >= 0 {
+			p.name = p.name[dot+1:] // Drop Apple's "item1." groups
+		}
+		for _, param := range parts[1:] {
+			key, paramVal, found := strings.Cut(param, "=")
+			if !found {
+				key, paramVal = "TYPE", key // vCard 2.1 writes "TEL;WORK;CELL:"
+			}
+			key = strings.ToUpper(key)
+			paramVal = strings.Trim(paramVal, `"`)
+			if p.params[key] != "" {
+				paramVal = p.params[key] + "," + paramVal // "TYPE=WORK;TYPE=CELL" is "TYPE=WORK,CELL"
+			}
+			p.params[key] = paramVal
+		}
+
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(value, "VCARD"):
+			began = true
+		case p.name == "END" && strings.EqualFold(value, "VCARD"):
+			ended = true
+		default:
+			properties = append(properties, p)
+		}
+	}
+	if !began || !ended {
+		return nil, errors.New("vcard: missing BEGIN:VCARD or END:VCARD")
+	}
+	return properties, nil
+}
+
+// =====================
+// ESCAPING AND FOLDING
+// =====================
+
+// escape protects the characters with a meaning in vCard values
+func escape(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(text)
+}
+
+// unescape undoes escape
+func unescape(text string) string {
+	var result strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			i++
+			if text[i] == 'n' || text[i] == 'N' {
+				result.WriteByte('\n')
+			} else {
+				result.WriteByte(text[i])
+			}
+			continue
+		}
+		result.WriteByte(text[i])
+	}
+	return result.String()
+}
+
+// splitUnescaped splits a structured value on unescaped separators and unescapes the parts
+func splitUnescaped(text string, separator byte) []string {
+	parts := []string{}
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if text[i] == separator {
+			parts = append(parts, unescape(text[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, unescape(text[start:]))
+}
+
+// paramValue quotes a parameter value when it contains ':', ';' or ','
+func paramValue(value string) string {
+	value = strings.ReplaceAll(value, `"`, "'") // Quotes cannot be escaped inside parameters
+	if strings.ContainsAny(value, ":;,") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// cutUnquoted cuts at the first separator outside double quotes
+func cutUnquoted(text string, separator byte) (string, string, bool) {
+	quoted := false
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '"':
+			quoted = !quoted
+		case text[i] == separator && !quoted:
+			return text[:i], text[i+1:], true
+		}
+	}
+	return text, "", false
+}
+
+// splitQuoted splits "NAME;A=1;B=\"x;y\"" on the semicolons outside quotes
+func splitQuoted(text string) []string {
+	parts := []string{}
+	for {
+		part, rest, found := cutUnquoted(text, ';')
+		parts = append(parts, part)
+		if !found {
+			return parts
+		}
+		text = rest
+	}
+}
+
+// nameParts builds the structured N value from a full name: "Sara de Vries" -> "de Vries;Sara;;;"
+func nameParts(name string) string {
+	given, family, found := strings.Cut(strings.TrimSpace(name), " ")
+	if !found {
+		return escape(given) + ";;;;" // Only one name: keep it as the family name
+	}
+	return escape(family) + ";" + escape(given) + ";;;"
+}
+
+// fold ends a line with CRLF and breaks it every 75 bytes (without splitting a UTF-8 character)
+func fold(line string) string {
+	var folded strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	folded.WriteString(line + "\r\n")
+	return folded.String()
+}
+
+// isRuneStart reports whether b is the first byte of a UTF-8 character
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
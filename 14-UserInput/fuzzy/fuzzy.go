@@ -0,0 +1,418 @@
+package fuzzy
+
+import (
+	"14-UserInput/structs"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// =====================
+// DAMERAU–LEVENSHTEIN DISTANCE
+// =====================
+
+// Distance counts the edits between two strings: inserting, deleting or
+// replacing a character, or swapping two neighbours ("Locaiton" -> "Location" is 1)
+// This is the unrestricted version: a swapped pair may be edited again afterwards
+func Distance(a string, b string) int {
+	s, t := []rune(a), []rune(b)
+	infinity := len(s) + len(t)
+
+	// d[i+1][j+1] is the distance between the first i runes of s and the first j of t;
+	// the extra row and column hold "infinity" so swaps at the start need no checks
+	d := make([][]int, len(s)+2)
+	for i := range d {
+		d[i] = make([]int, len(t)+2)
+	}
+	d[0][0] = infinity
+	for i := 0; i <= len(s); i++ {
+		d[i+1][0] = infinity
+		d[i+1][1] = i
+	}
+	for j := 0; j <= len(t); j++ {
+		d[0][j+1] = infinity
+		d[1][j+1] = j
+	}
+
+	lastRow := map[rune]int{} // Last row in which each rune of s was seen
+	for i := 1; i <= len(s); i++ {
+		lastColumn := 0 // Last column in this row where s[i-1] matched
+		for j := 1; j <= len(t); j++ {
+			swapRow, swapColumn := lastRow[t[j-1]], lastColumn
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+				lastColumn = j
+			}
+			d[i+1][j+1] = min(
+				d[i][j]+cost, // Replace (or keep)
+				d[i+1][j]+1,  // Insert
+				d[i][j+1]+1,  // Delete
+				d[swapRow][swapColumn]+(i-swapRow-1)+1+(j-swapColumn-1), // Swap, with the edits in between
+			)
+		}
+		lastRow[s[i-1]] = i
+	}
+	return d[len(s)+1][len(t)+1]
+}
+
+// Fold makes spellings comparable: lowercase, single spaces, no outer spaces
+func Fold(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// maxEdits is how many edits still count as a misspelling for a word of this length
+// Short words get none: "age" and "ago" are different words, not a typo
+func maxEdits(length int) int {
+	switch {
+	case length <= 3:
+		return 0
+	case length <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Close reports whether two spellings are probably the same word:
+// equal after folding, or a few edits apart
+// Text with digits or an "@" (apartments, phones, emails) only matches after folding
+func Close(a string, b string) bool {
+	a, b = Fold(a), Fold(b)
+	if a == b {
+		return true
+	}
+	if strings.ContainsFunc(a+b, func(r rune) bool { return unicode.IsDigit(r) || r == '@' }) {
+		return false
+	}
+	return Distance(a, b) <= maxEdits(min(len([]rune(a)), len([]rune(b))))
+}
+
+// =====================
+// INDEX OF KNOWN SPELLINGS
+// =====================
+
+// Variant is one spelling and how many times it is used
+type Variant struct {
+	Spelling string
+	Count    int
+	Known    bool // Known to be right (e.g., a place found in the gazetteer)
+}
+
+// String prints the variant: "Location" (12), or "Amsterdam" (3, known)
+func (variant Variant) String() string {
+	text := strconv.Quote(variant.Spelling) + " (" + strconv.Itoa(variant.Count)
+	if variant.Known {
+		text += ", known"
+	}
+	return text + ")"
+}
+
+// Group is a set of spellings of the same word
+// Canonical is the best one: a known spelling first, then the most used
+type Group struct {
+	Canonical Variant
+	Variants  []Variant // The other spellings, known ones first, then most used
+	Tied      bool      // Another spelling is as good as Canonical: someone has to choose
+}
+
+// String prints the group: "Location" (12) <- "location" (3), "Locaton" (1)
+func (group Group) String() string {
+	others := []string{}
+	for _, variant := range group.Variants {
+		others = append(others, variant.String())
+	}
+	return group.Canonical.String() + " <- " + strings.Join(others, ", ")
+}
+
+// Spellings lists every spelling in the group, Canonical first
+func (group Group) Spellings() []string {
+	spellings := []string{group.Canonical.Spelling}
+	for _, variant := range group.Variants {
+		spellings = append(spellings, variant.Spelling)
+	}
+	return spellings
+}
+
+// Index knows every Information key and value in use, and which spellings belong together
+// Normalize only rewrites the groups that were accepted
+type Index struct {
+	keys         map[string]int               // Key spelling -> uses
+	keyCanon     map[string]string            // Key spelling -> canonical key (itself when tied)
+	keyGroups    []Group                      // Keys written in more than one way
+	values       map[string]map[string]int    // Canonical key -> value spelling -> uses
+	valueCanon   map[string]map[string]string // Canonical key -> value spelling -> canonical value
+	valueGroups  map[string][]Group           // Canonical key -> values written in more than one way
+	keyRewrite   map[string]string            // Accepted: key spelling -> key to write
+	valueRewrite map[string]map[string]string // Accepted: canonical key -> value spelling -> value to write
+}
+
+// NewIndex collects the spellings used by people
+func NewIndex(people []structs.Person) *Index {
+	return NewIndexWith(people, nil)
+}
+
+// NewIndexWith is NewIndex with a way to tell right spellings from typos:
+// known(key, spelling) is asked with key "" for keys, and with the canonical key for values
+// A known spelling is canonical even when a typo is used more, and two different
+// known spellings ("Essen", "Essex") are never put together
+func NewIndexWith(people []structs.Person, known func(key string, spelling string) bool) *Index {
+	if known == nil {
+		known = func(string, string) bool { return false }
+	}
+	index := &Index{
+		keys:         map[string]int{},
+		values:       map[string]map[string]int{},
+		valueCanon:   map[string]map[string]string{},
+		valueGroups:  map[string][]Group{},
+		keyRewrite:   map[string]string{},
+		valueRewrite: map[string]map[string]string{},
+	}
+	for _, person := range people {
+		for key := range person.Information {
+			index.keys[key]++
+		}
+	}
+	index.keyCanon, index.keyGroups = canonicalize(index.keys, func(spelling string) bool { return known("", spelling) })
+
+	for _, person := range people {
+		for key, value := range person.Information {
+			canon := index.keyCanon[key]
+			if index.values[canon] == nil {
+				index.values[canon] = map[string]int{}
+			}
+			index.values[canon][value]++
+		}
+	}
+	for key, values := range index.values {
+		var found []Group
+		index.valueCanon[key], found = canonicalize(values, func(spelling string) bool { return known(key, spelling) })
+		if len(found) > 0 {
+			index.valueGroups[key] = found
+		}
+	}
+	return index
+}
+
+// canonicalize puts close spellings in groups and maps each spelling to the canonical
+// one of its group. Spellings are taken known first, then most used; each one joins
+// the closest group leader it is close to itself, or leads a new group. So there are
+// no chains: "Essen" and "Essex" stay apart even when "Esses" is close to both
+// A tied group (no best spelling) maps its spellings to themselves until one is accepted
+func canonicalize(counts map[string]int, known func(string) bool) (map[string]string, []Group) {
+	isKnown := map[string]bool{}
+	for spelling := range counts {
+		isKnown[spelling] = known(spelling)
+	}
+	spellings := sortedByUse(counts)
+	sort.SliceStable(spellings, func(i, j int) bool { return isKnown[spellings[i]] && !isKnown[spellings[j]] })
+
+	leaders := []*Group{}
+	for _, spelling := range spellings {
+		variant := Variant{Spelling: spelling, Count: counts[spelling], Known: isKnown[spelling]}
+		var leader *Group
+		bestDistance := -1
+		for _, group := range leaders {
+			canon := group.Canonical
+			if !Close(spelling, canon.Spelling) {
+				continue
+			}
+			if variant.Known && canon.Known && Fold(spelling) != Fold(canon.Spelling) {
+				continue // Two right spellings are two different words
+			}
+			distance := Distance(Fold(spelling), Fold(canon.Spelling))
+			if bestDistance < 0 || distance < bestDistance {
+				leader, bestDistance = group, distance
+			}
+		}
+		if leader == nil {
+			leaders = append(leaders, &Group{Canonical: variant})
+			continue
+		}
+		leader.Variants = append(leader.Variants, variant)
+		if variant.Known == leader.Canonical.Known && variant.Count == leader.Canonical.Count {
+			leader.Tied = true
+		}
+	}
+
+	canonical := map[string]string{}
+	result := []Group{}
+	for _, group := range leaders {
+		canonical[group.Canonical.Spelling] = group.Canonical.Spelling
+		for _, variant := range group.Variants {
+			canonical[variant.Spelling] = group.Canonical.Spelling
+			if group.Tied {
+				canonical[variant.Spelling] = variant.Spelling
+			}
+		}
+		if len(group.Variants) > 0 {
+			result = append(result, *group)
+		}
+	}
+	return canonical, result
+}
+
+// sortedByUse lists spellings from most to least used
+// (equal counts in sort order, only so the result is the same every run)
+func sortedByUse(counts map[string]int) []string {
+	spellings := make([]string, 0, len(counts))
+	for spelling := range counts {
+		spellings = append(spellings, spelling)
+	}
+	sort.Slice(spellings, func(i, j int) bool {
+		if counts[spellings[i]] != counts[spellings[j]] {
+			return counts[spellings[i]] > counts[spellings[j]]
+		}
+		return spellings[i] < spellings[j]
+	})
+	return spellings
+}
+
+// KeyGroups lists the keys written in more than one way
+// A Tied group has no canonical spelling yet: Accept one of its spellings to rewrite it
+func (index *Index) KeyGroups() []Group {
+	return index.keyGroups
+}
+
+// ValueGroups lists, per canonical key, the values written in more than one way
+func (index *Index) ValueGroups() map[string][]Group {
+	return index.valueGroups
+}
+
+// Accept makes Normalize rewrite every spelling of group to canonical
+// key is "" for a group from KeyGroups, or the key the group is listed under in ValueGroups
+func (index *Index) Accept(key string, group Group, canonical string) error {
+	spellings := group.Spellings()
+	if !slices.Contains(spellings, canonical) {
+		return fmt.Errorf("%q is not one of the spellings %q", canonical, spellings)
+	}
+	rewrite := index.keyRewrite
+	if key != "" {
+		if index.valueRewrite[key] == nil {
+			index.valueRewrite[key] = map[string]string{}
+		}
+		rewrite = index.valueRewrite[key]
+	}
+	for _, spelling := range spellings {
+		rewrite[spelling] = canonical
+	}
+	return nil
+}
+
+// =====================
+// SUGGESTIONS
+// =====================
+
+// SuggestKey returns the spelling to use instead of key, if there is a better one
+// "Locaton" -> "Location"; a key nobody has used yet and that looks like no other gives false
+func (index *Index) SuggestKey(key string) (string, bool) {
+	return suggest(key, index.keys, index.keyCanon)
+}
+
+// SuggestValue does the same for a value of a key ("Amesterdam" -> "Amsterdam")
+func (index *Index) SuggestValue(key string, value string) (string, bool) {
+	canon := index.canonicalKey(key)
+	return suggest(value, index.values[canon], index.valueCanon[canon])
+}
+
+// canonicalKey is the canonical spelling of key (itself when unknown)
+func (index *Index) canonicalKey(key string) string {
+	if suggestion, ok := index.SuggestKey(key); ok {
+		return suggestion
+	}
+	return key
+}
+
+// suggest finds the closest known spelling (fewest edits, then most used)
+// and returns the canonical spelling of its group
+func suggest(text string, counts map[string]int, canonical map[string]string) (string, bool) {
+	if canon, known := canonical[text]; known {
+		return canon, canon != text
+	}
+	best, bestDistance := "", -1
+	for _, spelling := range sortedByUse(counts) {
+		if !Close(text, spelling) {
+			continue
+		}
+		distance := Distance(Fold(text), Fold(spelling))
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = spelling, distance
+		}
+	}
+	if bestDistance < 0 {
+		return "", false
+	}
+	return canonical[best], true
+}
+
+// =====================
+// NORMALIZING
+// =====================
+
+// Normalize rewrites a person's keys and values in the accepted groups
+// Spellings in groups nobody accepted are left as they are
+// Two keys that become the same (e.g., "Location" and "location") keep both values
+// when they differ: the second one is saved as "Location 2"
+func (index *Index) Normalize(person structs.Person) (structs.Person, bool) {
+	changed := false
+	information := map[string]string{}
+	keys := make([]string, 0, len(person.Information))
+	for key := range person.Information {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		// Keys that are already canonical go first, so they keep their name
+		iCanon, jCanon := index.rewrittenKey(keys[i]) == keys[i], index.rewrittenKey(keys[j]) == keys[j]
+		if iCanon != jCanon {
+			return iCanon
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		value := person.Information[key]
+		canonKey := index.rewrittenKey(key)
+		if canon, ok := index.valueRewrite[index.keyCanon[key]][value]; ok {
+			value = canon
+		}
+
+		name := canonKey
+		for n := 2; ; n++ {
+			existing, taken := information[name]
+			if !taken || existing == value {
+				break
+			}
+			name = canonKey + " " + strconv.Itoa(n)
+		}
+		if name != key || value != person.Information[key] {
+			changed = true
+		}
+		information[name] = value
+	}
+
+	normalized := person
+	normalized.Information = information
+	return normalized, changed
+}
+
+// rewrittenKey is the key Normalize writes key as (itself when not accepted)
+func (index *Index) rewrittenKey(key string) string {
+	if canon, ok := index.keyRewrite[key]; ok {
+		return canon
+	}
+	return key
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// fuzzy.Distance("Locaton", "Location")  -> 1
+// fuzzy.NewIndex(people)                 -> known keys and values
+// fuzzy.NewIndexWith(people, known)      -> known spellings win over typos
+// index.Accept("", group, "Location")    -> Normalize rewrites that group
+// index.SuggestKey("locaton")            -> "Location", true
+// index.SuggestValue("city", "Amesterdam") -> "Amsterdam", true
+// index.Normalize(person)                -> person with the accepted spellings
@@ -0,0 +1,447 @@
+package gazetteer
+
+import (
+	"14-UserInput/fuzzy"
+	"14-UserInput/structs"
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// =====================
+// OFFLINE GAZETTEER
+// =====================
+// Locations are typed by hand: "Amsterdam", "A'dam", "amesterdam", "Amsterdam, NL"
+// The gazetteer knows countries and major cities with their names in other
+// languages, so all of those become the same place: Amsterdam in the Netherlands (NL)
+// The list is built into the program (places.txt), no network is needed
+
+//go:embed places.txt
+var placesFile string
+
+// Kind says whether a place is a country or a city
+type Kind int
+
+const (
+	Country Kind = iota
+	City
+)
+
+// String returns "country" or "city"
+func (kind Kind) String() string {
+	if kind == City {
+		return "city"
+	}
+	return "country"
+}
+
+// Place is one country or city
+type Place struct {
+	Name    string // Canonical name: "Amsterdam", "Netherlands"
+	Kind    Kind
+	Country string // ISO 3166-1 alpha-2 code: "NL"
+}
+
+// Label is how the place is written after normalizing: "Amsterdam, NL" or "Netherlands"
+// The country code keeps cities with the same name apart, and resolves to the same place again
+func (place Place) Label() string {
+	if place.Kind == City {
+		return place.Name + ", " + place.Country
+	}
+	return place.Name
+}
+
+// In reports whether place lies in area: a city in its country, or the place itself
+func (place Place) In(area Place) bool {
+	if area.Kind == Country {
+		return place.Country == area.Country
+	}
+	return place == area
+}
+
+// Match is a place found for a typed text
+type Match struct {
+	Place   Place
+	Guessed bool // Found by spelling distance ("Amesterdam"), not by a known name
+	Partial bool // Only part of the text is the place ("Keizersgracht 1, Amsterdam")
+}
+
+// Gazetteer finds places by name
+type Gazetteer struct {
+	places    []Place
+	names     map[string][]int // Folded name or alias -> places, in file order
+	spellings []spelling       // Every name and alias, for guessing typos
+	countries map[string]int   // Country code -> the country's place
+	codes     map[string]int   // Codes in capitals ("NL", "NLD", "UK") -> the country's place
+}
+
+// spelling is one name of a place
+type spelling struct {
+	folded string
+	place  int
+}
+
+// Load reads the places built into the program
+func Load() (*Gazetteer, error) {
+	return Parse(strings.NewReader(placesFile))
+}
+
+// Parse reads places in the places.txt format:
+// kind|country code|canonical name|other names, comma-separated
+// A country's code and its aliases of up to three capitals ("NLD", "UK") are codes,
+// not names: "no" and "is" are words, so codes are only found written in capitals
+func Parse(r io.Reader) (*Gazetteer, error) {
+	gazetteer := &Gazetteer{names: map[string][]int{}, countries: map[string]int{}, codes: map[string]int{}}
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "|")
+		if len(fields) != 4 || len(fields[1]) != 2 || fields[2] == "" {
+			return nil, fmt.Errorf("gazetteer: line %d: want kind|code|name|aliases, got %q", lineNumber, line)
+		}
+
+		place := Place{Name: fields[2], Country: strings.ToUpper(fields[1])}
+		switch fields[0] {
+		case "country":
+			place.Kind = Country
+		case "city":
+			place.Kind = City
+			if _, known := gazetteer.countries[place.Country]; !known {
+				return nil, fmt.Errorf("gazetteer: line %d: unknown country %s (list countries first)", lineNumber, place.Country)
+			}
+		default:
+			return nil, fmt.Errorf("gazetteer: line %d: kind must be country or city, got %q", lineNumber, fields[0])
+		}
+
+		index := len(gazetteer.places)
+		gazetteer.places = append(gazetteer.places, place)
+		names := []string{place.Name}
+		if place.Kind == Country {
+			gazetteer.countries[place.Country] = index
+			gazetteer.codes[place.Country] = index
+		}
+		if fields[3] != "" {
+			names = append(names, strings.Split(fields[3], ",")...)
+		}
+		for _, name := range names {
+			if place.Kind == Country && isCode(name) {
+				gazetteer.codes[name] = index
+				continue
+			}
+			gazetteer.add(name, index)
+		}
+	}
+	return gazetteer, scanner.Err()
+}
+
+// add makes name (and its folded spellings) lead to a place
+func (gazetteer *Gazetteer) add(name string, index int) {
+	folded := Fold(name)
+	if folded == "" {
+		return
+	}
+	for _, known := range gazetteer.names[folded] {
+		if known == index {
+			return // The same name twice (e.g., "Zürich" and "Zurich" both fold to "zurich")
+		}
+	}
+	gazetteer.names[folded] = append(gazetteer.names[folded], index)
+	gazetteer.spellings = append(gazetteer.spellings, spelling{folded, index})
+}
+
+// isCode reports whether name is written like a country code: two or three capitals
+func isCode(name string) bool {
+	if len(name) < 2 || len(name) > 3 {
+		return false
+	}
+	for _, r := range name {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// code finds the country a part of the text is the code of: "NL", "Utrecht NL"
+// Only capitals count, so "no" and "is" stay words
+// It returns the part without the code, and the country's folded name to look up instead
+func (gazetteer *Gazetteer) code(part string) (string, string, bool) {
+	words := strings.Fields(part)
+	if len(words) == 0 {
+		return part, "", false
+	}
+	index, ok := gazetteer.codes[strings.Trim(words[len(words)-1], ".")]
+	if !ok {
+		return part, "", false
+	}
+	return strings.Join(words[:len(words)-1], " "), Fold(gazetteer.places[index].Name), true
+}
+
+// Country returns the country with a code ("NL")
+func (gazetteer *Gazetteer) Country(code string) (Place, bool) {
+	index, ok := gazetteer.countries[strings.ToUpper(code)]
+	if !ok {
+		return Place{}, false
+	}
+	return gazetteer.places[index], true
+}
+
+// Len is the number of places known
+func (gazetteer *Gazetteer) Len() int {
+	return len(gazetteer.places)
+}
+
+// =====================
+// FOLDING
+// =====================
+
+// plainLetters lists the accented letters that are written without accent when folding
+var plainLetters = map[string]string{
+	"a": "àáâãäåāăąạảầấậ",
+	"c": "çćčĉ",
+	"d": "ďđ",
+	"e": "èéêëēĕėęěẹẻẽềếệ",
+	"g": "ğĝġģ",
+	"i": "ìíîïīĭįıỉị",
+	"l": "łľĺļ",
+	"n": "ñńňņ",
+	"o": "òóôõöøōŏőọỏồốộơờớợ",
+	"r": "řŕŗ",
+	"s": "śšşșŝ",
+	"t": "ťţț",
+	"u": "ùúûüūŭůűųụủưừứự",
+	"y": "ýÿŷỳ",
+	"z": "žźż",
+}
+
+// folder removes accents and the punctuation people leave out ("St. Petersburg", "A'dam")
+var folder = func() *strings.Replacer {
+	pairs := []string{
+		"ß", "ss", "æ", "ae", "œ", "oe", "þ", "th",
+		"\u0307", "", // The dot "İ" keeps after strings.ToLower
+		"'", "", "’", "", ".", "",
+		"-", " ", "_", " ",
+	}
+	for plain, accented := range plainLetters {
+		for _, letter := range accented {
+			pairs = append(pairs, string(letter), plain)
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// Fold makes place names comparable: lowercase, no accents, no dots or apostrophes,
+// hyphens as spaces ("Saint-Pétersbourg" -> "saint petersbourg")
+func Fold(name string) string {
+	return fuzzy.Fold(folder.Replace(strings.ToLower(name)))
+}
+
+// =====================
+// RESOLVING
+// =====================
+
+// Resolve finds the place a typed location means
+// "Amsterdam", "A'dam", "Amsterdã" and "Amesterdam" (a typo) all give Amsterdam, NL
+// Text with commas is tried whole first, then part by part ("Amsterdam, Netherlands"):
+// a city wins over a country, and a country part picks between cities with the same name
+// Country codes only count in capitals: "Oslo, NO" is Norway, "no" is not
+func (gazetteer *Gazetteer) Resolve(text string) (Match, bool) {
+	whole := Fold(text)
+	if whole == "" {
+		return Match{}, false
+	}
+	if found := gazetteer.names[whole]; len(found) > 0 {
+		return Match{Place: gazetteer.places[found[0]]}, true
+	}
+
+	parts := []string{}
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return strings.ContainsRune(",;/()", r) }) {
+		rest, country, isCode := gazetteer.code(part)
+		if folded := Fold(rest); folded != "" {
+			if isCode {
+				parts = append(parts, folded)
+			} else {
+				parts = append(parts, gazetteer.splitCountry(folded)...)
+			}
+		}
+		if isCode {
+			parts = append(parts, country)
+		}
+	}
+	if len(parts) == 0 {
+		return Match{}, false
+	}
+
+	// Countries named in the text decide between cities with the same name
+	context := map[string]bool{}
+	for _, part := range parts {
+		for _, index := range gazetteer.names[part] {
+			if gazetteer.places[index].Kind == Country {
+				context[gazetteer.places[index].Country] = true
+			}
+		}
+	}
+
+	match, found, used := gazetteer.exact(parts, context)
+	if !found {
+		match, found, used = gazetteer.guess(parts, context)
+	}
+	if !found {
+		return Match{}, false
+	}
+
+	// Partial: some part is neither the place nor its country ("Keizersgracht 1")
+	for i, part := range parts {
+		if i == used {
+			continue
+		}
+		other, known := gazetteer.exactPart(part, context)
+		if !known || other.Kind != Country || other.Country != match.Place.Country {
+			match.Partial = true
+		}
+	}
+	return match, true
+}
+
+// splitCountry splits a country off the end of a part without a comma:
+// "utrecht nl" -> "utrecht", "nl"; "den haag" stays as it is (a name on its own)
+func (gazetteer *Gazetteer) splitCountry(part string) []string {
+	if len(gazetteer.names[part]) > 0 {
+		return []string{part}
+	}
+	words := strings.Fields(part)
+	for size := min(3, len(words)-1); size >= 1; size-- {
+		tail := strings.Join(words[len(words)-size:], " ")
+		for _, index := range gazetteer.names[tail] {
+			if gazetteer.places[index].Kind == Country {
+				return []string{strings.Join(words[:len(words)-size], " "), tail}
+			}
+		}
+	}
+	return []string{part}
+}
+
+// exact looks every part up by name; the most specific place wins
+func (gazetteer *Gazetteer) exact(parts []string, context map[string]bool) (Match, bool, int) {
+	best, bestPart := Place{}, -1
+	for i, part := range parts {
+		place, ok := gazetteer.exactPart(part, context)
+		if ok && (bestPart < 0 || place.Kind == City && best.Kind == Country) {
+			best, bestPart = place, i
+		}
+	}
+	return Match{Place: best}, bestPart >= 0, bestPart
+}
+
+// exactPart is the place named part, preferring one in a context country
+func (gazetteer *Gazetteer) exactPart(part string, context map[string]bool) (Place, bool) {
+	found := gazetteer.names[part]
+	if len(found) == 0 {
+		return Place{}, false
+	}
+	for _, index := range found {
+		if context[gazetteer.places[index].Country] && gazetteer.places[index].Kind == City {
+			return gazetteer.places[index], true
+		}
+	}
+	return gazetteer.places[found[0]], true
+}
+
+// guess finds the closest spelling to any part (fuzzy.Close decides what is close enough)
+// Ties go to the place in a context country, then to the first one in the file
+func (gazetteer *Gazetteer) guess(parts []string, context map[string]bool) (Match, bool, int) {
+	type candidate struct {
+		place, part, distance int
+		inContext             bool
+	}
+	candidates := []candidate{}
+	for i, part := range parts {
+		for _, known := range gazetteer.spellings {
+			if !fuzzy.Close(part, known.folded) {
+				continue
+			}
+			place := gazetteer.places[known.place]
+			candidates = append(candidates, candidate{known.place, i, fuzzy.Distance(part, known.folded), context[place.Country]})
+		}
+	}
+	if len(candidates) == 0 {
+		return Match{}, false, -1
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.inContext != b.inContext {
+			return a.inContext
+		}
+		return a.place < b.place
+	})
+	best := candidates[0]
+	return Match{Place: gazetteer.places[best.place], Guessed: true}, true, best.part
+}
+
+// =====================
+// PEOPLE
+// =====================
+
+// locationKeys are the Information keys that hold a place
+var locationKeys = []string{"location", "city", "country", "place", "hometown", "residence", "town", "lives in", "address"}
+
+// IsLocationKey reports whether an Information key holds a place ("Location", "locaton", "City")
+func IsLocationKey(key string) bool {
+	for _, known := range locationKeys {
+		if fuzzy.Close(key, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// Located is a place found in one of a person's Information values
+type Located struct {
+	Key   string
+	Value string
+	Match Match
+}
+
+// Places resolves every location value of a person, sorted by key
+func (gazetteer *Gazetteer) Places(person structs.Person) []Located {
+	found := []Located{}
+	for key, value := range person.Information {
+		if !IsLocationKey(key) {
+			continue
+		}
+		if match, ok := gazetteer.Resolve(value); ok {
+			found = append(found, Located{key, value, match})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Key < found[j].Key })
+	return found
+}
+
+// Lives reports whether one of the person's locations lies in area
+func (gazetteer *Gazetteer) Lives(person structs.Person, area Place) (Located, bool) {
+	for _, located := range gazetteer.Places(person) {
+		if located.Match.Place.In(area) {
+			return located, true
+		}
+	}
+	return Located{}, false
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// gazetteer.Load()                     -> the built-in countries and cities
+// places.Resolve("A'dam")              -> Amsterdam, NL (city)
+// places.Resolve("Amesterdam")         -> Amsterdam, NL, Guessed: true
+// places.Resolve("Utrecht NL")         -> Utrecht, NL ("Utrecht nl" does not read "nl" as a code)
+// place.In(netherlands)                -> is Amsterdam in the Netherlands?
+// places.Lives(person, netherlands)    -> which location of the person is there
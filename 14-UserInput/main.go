@@ -1,24 +1,973 @@
 package main
 
 import (
+    "14-UserInput/anonymize"
+    "14-UserInput/blobs"
+    "14-UserInput/calendar"
+    "14-UserInput/carddav"
+    "14-UserInput/fuzzy"
+    "14-UserInput/gazetteer"
+    "14-UserInput/qr"
+    "14-UserInput/quick"
+    "14-UserInput/stats"
+    "14-UserInput/store"
     "14-UserInput/structs"
     "bufio"
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    "flag"
     "fmt"
+    "io"
+    "net/http"
     "os"
+    "path/filepath"
+    "sort"
     "strconv"
     "strings"
+    "time"
 )
 
 func main() {
     // =====================
-    // USER INPUT EXAMPLE
+    // COMMANDS
     // =====================
 
+    // os.Args[0] is the program name, os.Args[1] the command (if any)
+    // No command = the original interactive "add a person" flow
+    command := "add"
+    args := []string{}
+    if len(os.Args) > 1 {
+        command = os.Args[1]
+        args = os.Args[2:]
+    }
+
+    var err error
+    switch command {
+    case "add":
+        err = runAdd(args)
+    case "quick":
+        err = runQuick(args)
+    case "stats":
+        err = runStats(args)
+    case "serve":
+        err = runServe(args)
+    case "upcoming":
+        err = runUpcoming(args)
+    case "calendar":
+        err = runCalendar(args)
+    case "qr":
+        err = runQR(args)
+    case "attach":
+        err = runAttach(args)
+    case "detach":
+        err = runDetach(args)
+    case "export":
+        err = runExport(args)
+    case "gc":
+        err = runGC(args)
+    case "normalize":
+        err = runNormalize(args)
+    case "in":
+        err = runIn(args)
+    case "locations":
+        err = runLocations(args)
+    case "anonymize":
+        err = runAnonymize(args)
+    default:
+        err = fmt.Errorf("unknown command %q (use: add, quick, stats, serve, upcoming, calendar, qr, attach, detach, export, gc, normalize, in, locations, anonymize)", command)
+    }
+
+    if err != nil {
+        fmt.Fprintln(os.Stderr, "error:", err)
+        os.Exit(1)
+    }
+}
+
+// =====================
+// ADD COMMAND
+// =====================
+
+// runAdd asks for a new person, shows it and saves it to the store
+func runAdd(args []string) error {
+    flags := flag.NewFlagSet("add", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    flags.Parse(args)
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+
     // Create a person by asking the user for input
-    person := createPerson()
+    // The index knows the keys and values already saved, to catch typos
+    person := createPerson(fuzzy.NewIndex(people.People))
 
     // Display the person's formatted information
     fmt.Println("\n" + person.PersonFormattedInformation())
+
+    people.Add(person)
+    return people.Save()
+}
+
+// =====================
+// QUICK COMMAND
+// =====================
+
+// runQuick adds a person from one line: quick "Add Sara, 34, lives in Amsterdam, email sara@x.io"
+// Without a line it asks for one; the result is shown before it is saved
+func runQuick(args []string) error {
+    flags := flag.NewFlagSet("quick", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    yes := flags.Bool("yes", false, "save without asking")
+    flags.Parse(args)
+
+    reader := bufio.NewReader(os.Stdin)
+    line := strings.Join(flags.Args(), " ")
+    if line == "" {
+        fmt.Println("\nDescribe the person (e.g., Add Sara, 34, lives in Amsterdam, email sara@x.io): ")
+        line, _ = reader.ReadString('\n')
+    }
+
+    entry, err := quick.Parse(line)
+    if err != nil {
+        return err
+    }
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    fmt.Println("\n" + entry.Preview())
+
+    // Known spellings for keys and values that look misspelt
+    index := fuzzy.NewIndex(people.People)
+    information := map[string]string{}
+    changes := []string{}
+    for key, value := range entry.Information {
+        newKey, newValue := key, value
+        if suggestion, ok := index.SuggestKey(key); ok {
+            newKey = suggestion
+        }
+        if suggestion, ok := index.SuggestValue(newKey, value); ok {
+            newValue = suggestion
+        }
+        if _, taken := information[newKey]; taken || (newKey != key && entry.Information[newKey] != "") {
+            newKey = key // Would overwrite another key: keep it as typed
+        }
+        if newKey != key || newValue != value {
+            changes = append(changes, fmt.Sprintf("  %s: %s -> %s: %s", key, value, newKey, newValue))
+        }
+        information[newKey] = newValue
+    }
+    if len(changes) > 0 {
+        sort.Strings(changes)
+        fmt.Println("\nThese look like spellings already in use:\n" + strings.Join(changes, "\n"))
+        useKnown := *yes // --yes takes the known spellings too
+        if !useKnown {
+            fmt.Println("\nUse the known spellings? (yes/no)")
+            answer, _ := reader.ReadString('\n')
+            switch strings.ToUpper(strings.TrimSpace(answer)) {
+            case "YES", "Y":
+                useKnown = true
+            }
+        }
+        if useKnown {
+            entry.Information = information
+            fmt.Println("\n" + entry.Preview())
+        }
+    }
+
+    if !*yes {
+        fmt.Println("\nSave this person? (yes/no)")
+        answer, _ := reader.ReadString('\n')
+        switch strings.ToUpper(strings.TrimSpace(answer)) {
+        case "YES", "Y":
+        default:
+            fmt.Println("Not saved.")
+            return nil
+        }
+    }
+
+    people.Add(entry.Person())
+    if err := people.Save(); err != nil {
+        return err
+    }
+    fmt.Println("Saved", entry.Name)
+    return nil
+}
+
+// =====================
+// STATS COMMAND
+// =====================
+
+// runStats prints age statistics and the most used information
+func runStats(args []string) error {
+    options := stats.DefaultOptions()
+
+    flags := flag.NewFlagSet("stats", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    flags.IntVar(&options.BucketSize, "bucket", options.BucketSize, "age histogram bucket size in years")
+    flags.IntVar(&options.Top, "top", options.Top, "how many information keys and values to list")
+    percentiles := flags.String("percentiles", "25,50,75,90", "comma-separated percentiles to show")
+    flags.Parse(args)
+
+    options.Percentiles = nil
+    for _, field := range strings.Split(*percentiles, ",") {
+        rank, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+        if err != nil || rank < 0 || rank > 100 {
+            return fmt.Errorf("invalid percentile %q", field)
+        }
+        options.Percentiles = append(options.Percentiles, rank)
+    }
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+
+    fmt.Println(stats.NewReport(people.People, options).Format())
+    return nil
+}
+
+// =====================
+// SERVE COMMAND
+// =====================
+
+// runServe shares the people as a CardDAV address book for phones and mail clients
+func runServe(args []string) error {
+    flags := flag.NewFlagSet("serve", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    addr := flags.String("addr", "127.0.0.1:8080", "address to listen on")
+    flags.Parse(args)
+
+    // The birthday calendar is served next to the address book
+    mux := http.NewServeMux()
+    mux.Handle("/birthdays.ics", calendar.Handler(func() ([]structs.Person, error) {
+        people, err := store.Open(*storePath)
+        if err != nil {
+            return nil, err
+        }
+        return people.People, nil
+    }))
+    mux.Handle("/", carddav.NewServer(*storePath))
+
+    fmt.Printf("Address book: http://%s/addressbooks/people/\n", *addr)
+    fmt.Printf("Birthdays:    http://%s/birthdays.ics\n", *addr)
+    return http.ListenAndServe(*addr, mux)
+}
+
+// =====================
+// BIRTHDAY COMMANDS
+// =====================
+
+// runUpcoming lists the birthdays of the next days
+func runUpcoming(args []string) error {
+    flags := flag.NewFlagSet("upcoming", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    days := flags.Int("days", 30, "how many days ahead to look")
+    flags.Parse(args)
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    fmt.Println(calendar.FormatUpcoming(calendar.Upcoming(people.People, time.Now(), *days), *days))
+    return nil
+}
+
+// runCalendar writes every birthday to an iCalendar (.ics) file
+func runCalendar(args []string) error {
+    flags := flag.NewFlagSet("calendar", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    out := flags.String("out", "birthdays.ics", "file to write")
+    flags.Parse(args)
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    if err := os.WriteFile(*out, []byte(calendar.Export(people.People, time.Now())), 0644); err != nil {
+        return err
+    }
+    fmt.Println("Wrote", *out)
+    return nil
+}
+
+// =====================
+// QR COMMAND
+// =====================
+
+// runQR draws a QR code of a person's vCard, e.g., for an event badge
+// qr "Sara de Vries"                 -> draw it in the terminal
+// qr --out sara.png "Sara de Vries"  -> PNG (or .svg)
+// qr --all --dir badges              -> one PNG per person
+func runQR(args []string) error {
+    flags := flag.NewFlagSet("qr", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    out := flags.String("out", "", "write a .png or .svg file instead of drawing in the terminal")
+    levelName := flags.String("level", "M", "error correction level: L, M, Q or H")
+    scale := flags.Int("scale", 8, "pixels per module in PNG files")
+    invert := flags.Bool("invert", false, "terminal: dark modules as blocks (for light backgrounds)")
+    all := flags.Bool("all", false, "write a PNG for every person")
+    dir := flags.String("dir", "badges", "directory for --all")
+    flags.Parse(args)
+
+    level, err := qr.ParseLevel(*levelName)
+    if err != nil {
+        return err
+    }
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+
+    if *all {
+        if err := os.MkdirAll(*dir, 0755); err != nil {
+            return err
+        }
+        for _, person := range people.People {
+            path := filepath.Join(*dir, fileName(person.Name)+".png")
+            if err := writeQR(person, level, path, *scale); err != nil {
+                return err
+            }
+            fmt.Println("Wrote", path)
+        }
+        return nil
+    }
+
+    if flags.NArg() != 1 {
+        return fmt.Errorf("give one name or UID, e.g., qr \"Sara de Vries\" (or use --all)")
+    }
+    person, err := findPerson(people.People, flags.Arg(0))
+    if err != nil {
+        return err
+    }
+    if *out == "" {
+        code, err := qr.Encode([]byte(carddav.Encode(person)), level)
+        if err != nil {
+            return err
+        }
+        fmt.Print(code.Terminal(*invert))
+        return nil
+    }
+    if err := writeQR(person, level, *out, *scale); err != nil {
+        return err
+    }
+    fmt.Println("Wrote", *out)
+    return nil
+}
+
+// writeQR saves the QR code of a person's vCard as PNG or SVG (by file extension)
+func writeQR(person structs.Person, level qr.Level, path string, scale int) error {
+    code, err := qr.Encode([]byte(carddav.Encode(person)), level)
+    if err != nil {
+        return fmt.Errorf("%s: %w", person.Name, err)
+    }
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".svg":
+        return os.WriteFile(path, []byte(code.SVG((code.Size+2*qr.QuietZone)*scale)), 0644)
+    case ".png":
+        file, err := os.Create(path)
+        if err != nil {
+            return err
+        }
+        if err := code.WritePNG(file, scale); err != nil {
+            file.Close()
+            return err
+        }
+        return file.Close()
+    default:
+        return fmt.Errorf("%s: use a .png or .svg file", path)
+    }
+}
+
+// findPerson finds a person by UID or by name (ignoring case)
+func findPerson(people []structs.Person, nameOrUID string) (structs.Person, error) {
+    matches := []structs.Person{}
+    for _, person := range people {
+        if person.UID == nameOrUID {
+            return person, nil
+        }
+        if strings.EqualFold(person.Name, nameOrUID) {
+            matches = append(matches, person)
+        }
+    }
+    switch len(matches) {
+    case 0:
+        return structs.Person{}, fmt.Errorf("nobody called %q", nameOrUID)
+    case 1:
+        return matches[0], nil
+    default:
+        return structs.Person{}, fmt.Errorf("%d people are called %q; use the UID (e.g., %s)", len(matches), nameOrUID, matches[0].UID)
+    }
+}
+
+// fileName turns a name into a safe file name: "Sara de Vries" -> "sara-de-vries"
+func fileName(name string) string {
+    var safe strings.Builder
+    for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+        switch {
+        case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+            safe.WriteRune(r)
+        case r == ' ' || r == '-' || r == '_':
+            safe.WriteRune('-')
+        }
+    }
+    name = strings.Trim(safe.String(), "-")
+    if name == "" {
+        return "person"
+    }
+    return name
+}
+
+// =====================
+// ATTACHMENT COMMANDS
+// =====================
+
+// attachmentFlags adds the flags every attachment command has
+// The blobs live next to the store: people.json -> people.json.blobs/
+func attachmentFlags(name string) (*flag.FlagSet, *string, *string) {
+    flags := flag.NewFlagSet(name, flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    blobDir := flags.String("blobs", "", "directory of the attached files (default: <store>.blobs)")
+    return flags, storePath, blobDir
+}
+
+// openBlobs opens the blob store for a store file
+func openBlobs(storePath string, blobDir string) *blobs.Store {
+    if blobDir == "" {
+        blobDir = storePath + ".blobs"
+    }
+    return blobs.Open(blobDir)
+}
+
+// runAttach stores a file and adds it to a person: attach [--as avatar.jpg] <person> <file>
+func runAttach(args []string) error {
+    flags, storePath, blobDir := attachmentFlags("attach")
+    as := flags.String("as", "", "name of the attachment (default: the file name)")
+    mimeType := flags.String("type", "", "MIME type (default: guessed from the name and content)")
+    flags.Parse(args)
+    if flags.NArg() != 2 {
+        return fmt.Errorf("use: attach [--as name] <person> <file>")
+    }
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    person, err := findPerson(people.People, flags.Arg(0))
+    if err != nil {
+        return err
+    }
+
+    file, err := os.Open(flags.Arg(1))
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    attachment := structs.Attachment{Name: filepath.Base(flags.Arg(1)), MIME: *mimeType}
+    if *as != "" {
+        attachment.Name = filepath.Base(*as) // Names are used as file names by export
+    }
+    if attachment.MIME == "" {
+        head := make([]byte, 512)
+        n, _ := io.ReadFull(file, head)
+        attachment.MIME = blobs.DetectType(attachment.Name, head[:n])
+        if _, err := file.Seek(0, io.SeekStart); err != nil {
+            return err
+        }
+    }
+
+    attachment.Hash, attachment.Size, err = openBlobs(*storePath, *blobDir).Put(file)
+    if err != nil {
+        return err
+    }
+    _, replaced := person.Attachment(attachment.Name)
+    person.Attach(attachment)
+    people.Put(person)
+    if err := people.Save(); err != nil {
+        return err
+    }
+    fmt.Printf("Attached %s to %s (sha256 %s)\n", attachment, person.Name, attachment.Hash[:12])
+    if replaced {
+        return collectGarbage(people, openBlobs(*storePath, *blobDir))
+    }
+    return nil
+}
+
+// runDetach removes an attachment from a person: detach <person> <name>
+func runDetach(args []string) error {
+    flags, storePath, blobDir := attachmentFlags("detach")
+    flags.Parse(args)
+    if flags.NArg() != 2 {
+        return fmt.Errorf("use: detach <person> <attachment name>")
+    }
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    person, err := findPerson(people.People, flags.Arg(0))
+    if err != nil {
+        return err
+    }
+    if !person.Detach(flags.Arg(1)) {
+        return fmt.Errorf("%s has no attachment %q", person.Name, flags.Arg(1))
+    }
+    people.Put(person)
+    if err := people.Save(); err != nil {
+        return err
+    }
+    fmt.Printf("Detached %s from %s\n", flags.Arg(1), person.Name)
+    return collectGarbage(people, openBlobs(*storePath, *blobDir))
+}
+
+// runExport copies a person's attachments out of the blob store: export [--dir out] <person>
+func runExport(args []string) error {
+    flags, storePath, blobDir := attachmentFlags("export")
+    dir := flags.String("dir", "export", "directory to write the files to")
+    only := flags.String("name", "", "export only this attachment")
+    flags.Parse(args)
+    if flags.NArg() != 1 {
+        return fmt.Errorf("use: export [--dir out] [--name avatar.jpg] <person>")
+    }
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    person, err := findPerson(people.People, flags.Arg(0))
+    if err != nil {
+        return err
+    }
+    if *only != "" {
+        if _, ok := person.Attachment(*only); !ok {
+            return fmt.Errorf("%s has no attachment %q", person.Name, *only)
+        }
+    }
+    if len(person.Attachments) == 0 {
+        fmt.Println(person.Name, "has no attachments.")
+        return nil
+    }
+    if err := os.MkdirAll(*dir, 0755); err != nil {
+        return err
+    }
+
+    blobStore := openBlobs(*storePath, *blobDir)
+    for _, attachment := range person.Attachments {
+        if *only != "" && attachment.Name != *only {
+            continue
+        }
+        path := filepath.Join(*dir, attachment.Name)
+        if err := exportBlob(blobStore, attachment.Hash, path); err != nil {
+            return fmt.Errorf("%s: %w", attachment.Name, err)
+        }
+        fmt.Println("Wrote", path)
+    }
+    return nil
+}
+
+// exportBlob copies one blob to a file, checking the hash first
+func exportBlob(blobStore *blobs.Store, hash string, path string) error {
+    if err := blobStore.Verify(hash); err != nil {
+        return err
+    }
+    blob, err := blobStore.Get(hash)
+    if err != nil {
+        return err
+    }
+    defer blob.Close()
+
+    file, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if _, err := io.Copy(file, blob); err != nil {
+        file.Close()
+        return err
+    }
+    return file.Close()
+}
+
+// runGC deletes blobs no person points to (e.g., after a person was deleted)
+func runGC(args []string) error {
+    flags, storePath, blobDir := attachmentFlags("gc")
+    flags.Parse(args)
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    return collectGarbage(people, openBlobs(*storePath, *blobDir))
+}
+
+// collectGarbage removes the blobs not referenced by anyone in the store
+func collectGarbage(people *store.Store, blobStore *blobs.Store) error {
+    referenced := map[string]bool{}
+    for _, person := range people.People {
+        for _, attachment := range person.Attachments {
+            referenced[attachment.Hash] = true
+        }
+    }
+    result, err := blobStore.GC(referenced)
+    if err != nil {
+        return err
+    }
+    if len(result.Removed) > 0 {
+        fmt.Printf("Removed %d unused file(s), freed %s\n", len(result.Removed), structs.FormatSize(result.Freed))
+    }
+    return nil
+}
+
+// =====================
+// NORMALIZE COMMAND
+// =====================
+
+// runNormalize finds keys and values written in several ways ("Location",
+// "location", "Locaton") and asks group by group which spelling to rewrite them to
+// Place names found in the gazetteer win over typos; with --yes only the groups
+// that have one best spelling are rewritten
+func runNormalize(args []string) error {
+    flags := flag.NewFlagSet("normalize", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    yes := flags.Bool("yes", false, "rewrite every group that has one best spelling without asking")
+    flags.Parse(args)
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    places, err := gazetteer.Load()
+    if err != nil {
+        return err
+    }
+    index := fuzzy.NewIndexWith(people.People, knownPlaces(places))
+
+    keyGroups := index.KeyGroups()
+    valueGroups := index.ValueGroups()
+    if len(keyGroups) == 0 && len(valueGroups) == 0 {
+        fmt.Println("Every key and value is spelt one way. Nothing to do.")
+        return nil
+    }
+
+    reader := bufio.NewReader(os.Stdin)
+    if len(keyGroups) > 0 {
+        fmt.Println("Keys:")
+        for _, group := range keyGroups {
+            if canonical, ok := chooseSpelling(reader, group, *yes); ok {
+                index.Accept("", group, canonical)
+            }
+        }
+    }
+    keys := make([]string, 0, len(valueGroups))
+    for key := range valueGroups {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    for _, key := range keys {
+        fmt.Printf("Values of %q:\n", key)
+        for _, group := range valueGroups[key] {
+            if canonical, ok := chooseSpelling(reader, group, *yes); ok {
+                index.Accept(key, group, canonical)
+            }
+        }
+    }
+
+    changed := 0
+    for i, person := range people.People {
+        var personChanged bool
+        people.People[i], personChanged = index.Normalize(person)
+        if personChanged {
+            changed++
+        }
+    }
+    if changed == 0 {
+        fmt.Println("\nNothing changed.")
+        return nil
+    }
+    if err := people.Save(); err != nil {
+        return err
+    }
+    fmt.Printf("\nRewrote %d people.\n", changed)
+    return nil
+}
+
+// chooseSpelling shows a group and asks which spelling to keep
+// A group with one best spelling asks yes/no (or the number of another spelling);
+// a tied group has to be chosen by number, and is skipped with --yes
+func chooseSpelling(reader *bufio.Reader, group fuzzy.Group, yes bool) (string, bool) {
+    fmt.Println("  " + group.String())
+    spellings := group.Spellings()
+    if yes {
+        if group.Tied {
+            fmt.Println("    Skipped: no spelling is better than the others (run without --yes to choose)")
+            return "", false
+        }
+        return group.Canonical.Spelling, true
+    }
+
+    for i, spelling := range spellings {
+        fmt.Printf("    %d. %q\n", i+1, spelling)
+    }
+    if group.Tied {
+        fmt.Println("    Which spelling is right? (number, or enter to leave them as they are)")
+    } else {
+        fmt.Printf("    Rewrite to %q? (yes/no, or the number of another spelling)\n", group.Canonical.Spelling)
+    }
+    answer, _ := reader.ReadString('\n')
+    answer = strings.ToUpper(strings.TrimSpace(answer))
+    if number, err := strconv.Atoi(answer); err == nil && number >= 1 && number <= len(spellings) {
+        return spellings[number-1], true
+    }
+    if !group.Tied && (answer == "YES" || answer == "Y") {
+        return group.Canonical.Spelling, true
+    }
+    return "", false
+}
+
+// knownPlaces tells the fuzzy index which location values are right:
+// a place the gazetteer finds without guessing ("Essen", not "Esen")
+func knownPlaces(places *gazetteer.Gazetteer) func(key string, spelling string) bool {
+    return func(key string, spelling string) bool {
+        if key == "" || !gazetteer.IsLocationKey(key) {
+            return false
+        }
+        match, ok := places.Resolve(spelling)
+        return ok && !match.Guessed
+    }
+}
+
+// =====================
+// LOCATION COMMANDS
+// =====================
+
+// runIn lists everyone whose location lies in a place:
+// "in Netherlands" finds people who typed "Amsterdam", "A'dam" or "Amesterdam"
+func runIn(args []string) error {
+    flags := flag.NewFlagSet("in", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    flags.Parse(args)
+
+    query := strings.Join(flags.Args(), " ")
+    if query == "" {
+        return fmt.Errorf("use: in <country or city>, e.g., in \"the Netherlands\"")
+    }
+    places, err := gazetteer.Load()
+    if err != nil {
+        return err
+    }
+    area, ok := places.Resolve(query)
+    if !ok {
+        return fmt.Errorf("unknown place %q", query)
+    }
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+
+    fmt.Printf("Everyone in %s (%s):\n", area.Place.Name, area.Place.Country)
+    found := 0
+    for _, person := range people.People {
+        located, ok := places.Lives(person, area.Place)
+        if !ok {
+            continue
+        }
+        found++
+        note := ""
+        if located.Match.Guessed {
+            note = ", guessed"
+        }
+        fmt.Printf("  %-20s %-22s (%s: %q%s)\n", person.Name, located.Match.Place.Label(), located.Key, located.Value, note)
+    }
+    if found == 0 {
+        fmt.Println("  nobody")
+    }
+    return nil
+}
+
+// runLocations shows which place every location value resolves to,
+// and rewrites them to one spelling ("A'dam" -> "Amsterdam, NL") after asking
+func runLocations(args []string) error {
+    flags := flag.NewFlagSet("locations", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    yes := flags.Bool("yes", false, "rewrite without asking")
+    flags.Parse(args)
+
+    places, err := gazetteer.Load()
+    if err != nil {
+        return err
+    }
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+
+    // Every distinct value once, with the people using it
+    uses := map[string]int{}
+    matches := map[string]gazetteer.Match{}
+    unknown := map[string]int{}
+    for _, person := range people.People {
+        for key, value := range person.Information {
+            if !gazetteer.IsLocationKey(key) {
+                continue
+            }
+            if match, ok := places.Resolve(value); ok {
+                uses[value]++
+                matches[value] = match
+            } else {
+                unknown[value]++
+            }
+        }
+    }
+    if len(uses) == 0 && len(unknown) == 0 {
+        fmt.Println("Nobody has a location yet.")
+        return nil
+    }
+
+    values := make([]string, 0, len(uses))
+    for value := range uses {
+        values = append(values, value)
+    }
+    sort.Slice(values, func(i, j int) bool {
+        a, b := matches[values[i]].Place.Label(), matches[values[j]].Place.Label()
+        if a != b {
+            return a < b
+        }
+        return values[i] < values[j]
+    })
+    rewrites := 0
+    for _, value := range values {
+        match := matches[value]
+        note := ""
+        switch {
+        case match.Partial:
+            note = " (part of the text, kept)"
+        case match.Guessed:
+            note = " (guessed)"
+        }
+        if !match.Partial && value != match.Place.Label() {
+            rewrites += uses[value]
+        }
+        fmt.Printf("  %-28q -> %-22s x%d%s\n", value, match.Place.Label(), uses[value], note)
+    }
+    for _, value := range sortedKeys(unknown) {
+        fmt.Printf("  %-28q -> unknown place x%d\n", value, unknown[value])
+    }
+
+    if rewrites == 0 {
+        fmt.Println("\nEvery known location is already written the same way.")
+        return nil
+    }
+    if !*yes {
+        fmt.Printf("\nRewrite %d values to the names above? (yes/no)\n", rewrites)
+        answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+        switch strings.ToUpper(strings.TrimSpace(answer)) {
+        case "YES", "Y":
+        default:
+            fmt.Println("Nothing changed.")
+            return nil
+        }
+    }
+
+    for _, person := range people.People {
+        for key, value := range person.Information {
+            if match, ok := matches[value]; ok && gazetteer.IsLocationKey(key) && !match.Partial {
+                person.Information[key] = match.Place.Label()
+            }
+        }
+    }
+    if err := people.Save(); err != nil {
+        return err
+    }
+    fmt.Printf("Rewrote %d values.\n", rewrites)
+    return nil
+}
+
+// sortedKeys returns the keys of a count map in order
+func sortedKeys(counts map[string]int) []string {
+    keys := make([]string, 0, len(counts))
+    for key := range counts {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// =====================
+// ANONYMIZE COMMAND
+// =====================
+
+// runAnonymize writes a table of the people that can be shared without exposing them
+func runAnonymize(args []string) error {
+    options := anonymize.DefaultOptions()
+
+    flags := flag.NewFlagSet("anonymize", flag.ExitOnError)
+    storePath := flags.String("store", store.DefaultPath, "file the people are saved in")
+    out := flags.String("out", "people-anonymous.csv", "CSV file to write")
+    keyPath := flags.String("key", "", "file with the pseudonym secret (default: <store>.pseudonym-key)")
+    flags.IntVar(&options.K, "k", options.K, "every record looks like at least k-1 others")
+    flags.IntVar(&options.AgeBucket, "bucket", options.AgeBucket, "width of the age ranges in years")
+    flags.Parse(args)
+
+    if *keyPath == "" {
+        *keyPath = *storePath + ".pseudonym-key"
+    }
+    secret, err := pseudonymSecret(*keyPath)
+    if err != nil {
+        return err
+    }
+    options.Secret = secret
+    if options.Places, err = gazetteer.Load(); err != nil {
+        return err
+    }
+
+    people, err := store.Open(*storePath)
+    if err != nil {
+        return err
+    }
+    dataset, err := anonymize.Export(people.People, options)
+    if err != nil {
+        return err
+    }
+
+    // Write to a temporary file first, like the store, so a failed export leaves no half table
+    tmp := *out + ".tmp"
+    file, err := os.Create(tmp)
+    if err != nil {
+        return err
+    }
+    err = dataset.WriteCSV(file)
+    if closeErr := file.Close(); err == nil {
+        err = closeErr
+    }
+    if err == nil {
+        err = os.Rename(tmp, *out)
+    }
+    if err != nil {
+        os.Remove(tmp)
+        return err
+    }
+
+    fmt.Println(dataset.Report.Format())
+    fmt.Printf("\nWrote %s. Pseudonyms stay the same while %s is kept (never share it).\n", *out, *keyPath)
+    return nil
+}
+
+// pseudonymSecret reads the secret for the pseudonyms, or makes a new random one
+func pseudonymSecret(path string) ([]byte, error) {
+    text, err := os.ReadFile(path)
+    if err == nil {
+        return hex.DecodeString(strings.TrimSpace(string(text)))
+    }
+    if !errors.Is(err, os.ErrNotExist) {
+        return nil, err
+    }
+    secret := make([]byte, 32)
+    if _, err := rand.Read(secret); err != nil {
+        return nil, err
+    }
+    return secret, os.WriteFile(path, []byte(hex.EncodeToString(secret)+"\n"), 0600)
 }
 
 // =====================
@@ -26,8 +975,9 @@ func main() {
 // =====================
 
 // createPerson asks the user for name, age, and optional extra info
+// Keys and values that look like saved ones with a typo get a suggestion
 // Returns a fully constructed Person struct
-func createPerson() structs.Person {
+func createPerson(index *fuzzy.Index) structs.Person {
     // Control flags for loops
     loopAddInformation := true
     loopAddExtraInfo := false
@@ -51,6 +1001,20 @@ func createPerson() structs.Person {
     ageStr = strings.TrimSpace(ageStr)
     age, _ := strconv.Atoi(ageStr)       // Convert string to int
 
+    // =====================
+    // GET BIRTHDAY (OPTIONAL)
+    // =====================
+    fmt.Println("\nPerson birth date? (YYYY-MM-DD, or empty to skip) ")
+    birthday := ""
+    for {
+        birthday, _ = reader.ReadString('\n')
+        birthday = strings.TrimSpace(birthday)
+        if _, err := time.Parse(structs.DateLayout, birthday); birthday == "" || err == nil {
+            break
+        }
+        fmt.Println("\nWrite the date as YYYY-MM-DD, e.g., 1990-04-17.")
+    }
+
     // =====================
     // ASK FOR EXTRA INFO (OPTIONAL)
     // =====================
@@ -86,28 +1050,38 @@ func createPerson() structs.Person {
         fmt.Println("\nWhat type of info?")
         typeOfInfo, _ := reader.ReadString('\n')
         typeOfInfo = strings.TrimSpace(typeOfInfo)
+        if suggestion, ok := index.SuggestKey(typeOfInfo); ok {
+            typeOfInfo = offerSuggestion(reader, typeOfInfo, suggestion)
+        }
 
         // Get the details (e.g., "Amsterdam", "test@test.com")
         fmt.Println("\nWrite Detail about info")
         detailsOfInfo, _ := reader.ReadString('\n')
         detailsOfInfo = strings.TrimSpace(detailsOfInfo)
+        if suggestion, ok := index.SuggestValue(typeOfInfo, detailsOfInfo); ok {
+            detailsOfInfo = offerSuggestion(reader, detailsOfInfo, suggestion)
+        }
 
         // Add to the map
         information[typeOfInfo] = detailsOfInfo
 
         // Ask if user wants to add more
         fmt.Println("\nDo you want add more info? answer by yes or no")
-        addMoreInfo, _ := reader.ReadString('\n')
-        addMoreInfo = strings.TrimSpace(addMoreInfo)
-        addMoreInfo = strings.ToUpper(addMoreInfo)
 
-        // Validate yes/no answer
-        for loopAddExtraInfo {
+        // Validate yes/no answer (ask again until it is one of them)
+        validAnswer := false
+        for !validAnswer {
+            addMoreInfo, _ := reader.ReadString('\n')
+            addMoreInfo = strings.TrimSpace(addMoreInfo)
+            addMoreInfo = strings.ToUpper(addMoreInfo)
+
             switch addMoreInfo {
             case "NO":
                 loopAddExtraInfo = false // Stop adding info
+                validAnswer = true
             case "YES":
                 fmt.Println("\nYou will be getting same questions to add more information.")
+                validAnswer = true
             default:
                 fmt.Println("\nYou must answer by Yes or No")
             }
@@ -117,7 +1091,22 @@ func createPerson() structs.Person {
     fmt.Println("\nPerson obj made.")
 
     // Return the new Person using the constructor
-    return structs.NewPerson(name, age, information)
+    person := structs.NewPerson(name, age, information)
+    person.Birthday = birthday
+    return person
+}
+
+// offerSuggestion asks whether to use a known spelling instead of what was typed
+// Enter or yes takes the suggestion; anything else keeps the typed text
+func offerSuggestion(reader *bufio.Reader, typed string, suggestion string) string {
+    fmt.Printf("\nDid you mean %q instead of %q? (yes/no, Enter = yes)\n", suggestion, typed)
+    answer, _ := reader.ReadString('\n')
+    switch strings.ToUpper(strings.TrimSpace(answer)) {
+    case "", "YES", "Y":
+        return suggestion
+    default:
+        return typed
+    }
 }
 
 // =====================
@@ -127,4 +1116,7 @@ func createPerson() structs.Person {
 // reader.ReadString('\n')    -> read until Enter is pressed
 // strings.TrimSpace(s)       -> remove whitespace and newlines
 // strings.ToUpper(s)         -> convert to uppercase
-// strconv.Atoi(s)            -> convert string to int
\ No newline at end of file
+// strconv.Atoi(s)            -> convert string to int
+// flag.NewFlagSet(name, ...) -> parse flags for one command (e.g., stats --bucket 5)
+// http.ListenAndServe(a, h)  -> serve HTTP (serve command, CardDAV)
+// time.Parse(layout, s)      -> parse a date (birthdays)
\ No newline at end of file
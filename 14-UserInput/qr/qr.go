@@ -0,0 +1,455 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// =====================
+// QR CODE TYPES
+// =====================
+
+// Level is the error correction level: how much of the code may be damaged
+type Level int
+
+const (
+	L Level = iota // About 7% can be restored
+	M              // About 15%
+	Q              // About 25%
+	H              // About 30%
+)
+
+// String returns "L", "M", "Q" or "H"
+func (level Level) String() string {
+	return [...]string{"L", "M", "Q", "H"}[level]
+}
+
+// ParseLevel reads "L", "M", "Q" or "H" (any case)
+func ParseLevel(text string) (Level, error) {
+	for _, level := range []Level{L, M, Q, H} {
+		if strings.ToUpper(strings.TrimSpace(text)) == level.String() {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("qr: unknown error correction level %q (use L, M, Q or H)", text)
+}
+
+// formatBits is how the level is written in the format information
+func (level Level) formatBits() int {
+	return [...]int{1, 0, 3, 2}[level]
+}
+
+// ErrTooLong means the data does not fit in version 40 at the chosen level
+var ErrTooLong = errors.New("qr: data too long")
+
+// Code is an encoded QR code: a square of dark and light modules
+type Code struct {
+	Version int   // 1-40; the code is 4*Version+17 modules wide
+	Level   Level // Error correction level
+	Mask    int   // 0-7, the mask pattern that was chosen
+	Size    int   // Width and height in modules
+
+	modules  []bool // Dark modules, row by row
+	function []bool // Modules of the fixed patterns (not data); only used while encoding
+}
+
+// Dark reports whether the module at column x, row y is dark
+// Modules outside the code (the quiet zone) are light
+func (code *Code) Dark(x int, y int) bool {
+	if x < 0 || y < 0 || x >= code.Size || y >= code.Size {
+		return false
+	}
+	return code.modules[y*code.Size+x]
+}
+
+// =====================
+// ENCODING
+// =====================
+
+// Encode makes the smallest QR code holding data in byte mode
+// Byte mode stores any bytes; readers treat them as UTF-8 text
+func Encode(data []byte, level Level) (*Code, error) {
+	return encode(data, level, 1, -1)
+}
+
+// encode builds the code with at least minVersion, with a mask (or the best one for -1)
+func encode(data []byte, level Level, minVersion int, mask int) (*Code, error) {
+	version := minVersion
+	for ; version <= 40; version++ {
+		if bitsNeeded(len(data), version) <= dataCodewords(version, level)*8 {
+			break
+		}
+	}
+	if version > 40 {
+		return nil, fmt.Errorf("%w: %d bytes, at most %d fit at level %s", ErrTooLong, len(data), capacity(level), level)
+	}
+
+	codewords := addErrorCorrection(dataBits(data, version, level), version, level)
+
+	size := 4*version + 17
+	code := &Code{Version: version, Level: level, Size: size, modules: make([]bool, size*size), function: make([]bool, size*size)}
+	code.drawFunctionPatterns()
+	code.drawCodewords(codewords)
+
+	if mask < 0 {
+		// Try all eight masks and keep the one that is easiest to scan
+		best := 0
+		lowest := -1
+		for candidate := 0; candidate < 8; candidate++ {
+			code.applyMask(candidate)
+			code.drawFormatBits(candidate)
+			if score := code.penalty(); lowest < 0 || score < lowest {
+				best, lowest = candidate, score
+			}
+			code.applyMask(candidate) // Masking twice undoes it
+		}
+		mask = best
+	}
+	code.Mask = mask
+	code.applyMask(mask)
+	code.drawFormatBits(mask)
+	code.function = nil
+	return code, nil
+}
+
+// bitsNeeded is the size of the bit stream: mode, length and 8 bits per byte
+func bitsNeeded(length int, version int) int {
+	return 4 + countBits(version) + 8*length
+}
+
+// countBits is the width of the length field in byte mode
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// capacity is the number of bytes version 40 holds at a level
+func capacity(level Level) int {
+	return (dataCodewords(40, level)*8 - 4 - countBits(40)) / 8
+}
+
+// dataBits writes the bit stream and fills it up to the data capacity
+func dataBits(data []byte, version int, level Level) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4) // Byte mode
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacityBits := dataCodewords(version, level) * 8
+	bits.append(0, min(4, capacityBits-bits.length)) // Terminator
+	bits.append(0, (8-bits.length%8)%8)              // Up to a whole byte
+	for pad := 0xEC; bits.length < capacityBits; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8) // Alternating 11101100 00010001
+	}
+	return bits.bytes
+}
+
+// bitBuffer collects bits, most significant first
+type bitBuffer struct {
+	bytes  []byte
+	length int // In bits
+}
+
+// append adds the lowest count bits of value
+func (bits *bitBuffer) append(value int, count int) {
+	for i := count - 1; i >= 0; i-- {
+		if bits.length%8 == 0 {
+			bits.bytes = append(bits.bytes, 0)
+		}
+		if value>>i&1 == 1 {
+			bits.bytes[bits.length/8] |= 0x80 >> (bits.length % 8)
+		}
+		bits.length++
+	}
+}
+
+// addErrorCorrection splits the data into blocks, adds Reed–Solomon codewords
+// to each and interleaves them, so damage in one spot hits several blocks a little
+func addErrorCorrection(data []byte, version int, level Level) []byte {
+	blocks := blockCount[level][version]
+	eccLength := eccPerBlock[level][version]
+	total := totalCodewords(version)
+	shortBlocks := blocks - total%blocks // The others hold one more data codeword
+	shortLength := total/blocks - eccLength
+
+	gen := generator(eccLength)
+	dataBlocks := make([][]byte, blocks)
+	eccBlocks := make([][]byte, blocks)
+	for i, start := 0, 0; i < blocks; i++ {
+		length := shortLength
+		if i >= shortBlocks {
+			length++
+		}
+		dataBlocks[i] = data[start : start+length]
+		eccBlocks[i] = remainder(dataBlocks[i], gen)
+		start += length
+	}
+
+	result := make([]byte, 0, total)
+	for i := 0; i <= shortLength; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < eccLength; i++ {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// =====================
+// DRAWING
+// =====================
+
+// set colours a fixed-pattern module
+func (code *Code) set(x int, y int, dark bool) {
+	code.modules[y*code.Size+x] = dark
+	code.function[y*code.Size+x] = true
+}
+
+// drawFunctionPatterns draws everything that is not data
+func (code *Code) drawFunctionPatterns() {
+	size := code.Size
+
+	// Timing patterns: alternating modules along row and column 6
+	for i := 0; i < size; i++ {
+		code.set(6, i, i%2 == 0)
+		code.set(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns in three corners, with their light separators
+	for _, center := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := center[0]+dx, center[1]+dy
+				if x >= 0 && y >= 0 && x < size && y < size {
+					distance := max(abs(dx), abs(dy))
+					code.set(x, y, distance != 2 && distance != 4)
+				}
+			}
+		}
+	}
+
+	// Alignment patterns on a grid, except where the finders are
+	positions := alignmentPositions(code.Version)
+	last := len(positions) - 1
+	for i, cy := range positions {
+		for j, cx := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					code.set(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format information (drawn for real after masking)
+	code.drawFormatBits(0)
+
+	// Version information, versions 7 and up: the version with a BCH(18, 6) code
+	if code.Version >= 7 {
+		remainder := code.Version
+		for i := 0; i < 12; i++ {
+			remainder = remainder<<1 ^ (remainder>>11)*0x1F25
+		}
+		bits := code.Version<<12 | remainder
+		for i := 0; i < 18; i++ {
+			dark := bits>>i&1 == 1
+			a, b := size-11+i%3, i/3
+			code.set(a, b, dark)
+			code.set(b, a, dark)
+		}
+	}
+}
+
+// drawFormatBits writes the level and mask, twice, with a BCH(15, 5) code
+func (code *Code) drawFormatBits(mask int) {
+	data := code.Level.formatBits()<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = remainder<<1 ^ (remainder>>9)*0x537
+	}
+	bits := (data<<10 | remainder) ^ 0x5412 // The xor keeps the bits from being all zero
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	size := code.Size
+	// Around the top left finder
+	for i := 0; i <= 5; i++ {
+		code.set(8, i, bit(i))
+	}
+	code.set(8, 7, bit(6))
+	code.set(8, 8, bit(7))
+	code.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		code.set(14-i, 8, bit(i))
+	}
+	// Split between the other two finders
+	for i := 0; i < 8; i++ {
+		code.set(size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		code.set(8, size-15+i, bit(i))
+	}
+	code.set(8, size-8, true) // The "dark module", always dark
+}
+
+// drawCodewords fills the data area in the zigzag order of the standard:
+// two columns at a time, from the right, going up and down in turns
+func (code *Code) drawCodewords(codewords []byte) {
+	size := code.Size
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vertical := 0; vertical < size; vertical++ {
+			y := vertical
+			if upward {
+				y = size - 1 - vertical
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if code.function[y*size+x] || i >= len(codewords)*8 {
+					continue // Left over modules stay light
+				}
+				code.modules[y*size+x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules where the mask pattern is true
+func (code *Code) applyMask(mask int) {
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip && !code.function[y*code.Size+x] {
+				code.modules[y*code.Size+x] = !code.modules[y*code.Size+x]
+			}
+		}
+	}
+}
+
+// =====================
+// MASK PENALTY
+// =====================
+
+// penalty scores how hard the code is to scan (lower is better)
+func (code *Code) penalty() int {
+	size := code.Size
+	score := 0
+
+	for line := 0; line < size; line++ {
+		score += linePenalty(size, func(i int) bool { return code.Dark(i, line) })
+		score += linePenalty(size, func(i int) bool { return code.Dark(line, i) })
+	}
+
+	// 2x2 blocks of one colour
+	for y := 0; y < size-1; y++ {
+		for x := 0; x < size-1; x++ {
+			dark := code.Dark(x, y)
+			if dark == code.Dark(x+1, y) && dark == code.Dark(x, y+1) && dark == code.Dark(x+1, y+1) {
+				score += 3
+			}
+		}
+	}
+
+	// Balance of dark and light: 10 points for every 5% away from half
+	dark := 0
+	for _, module := range code.modules {
+		if module {
+			dark++
+		}
+	}
+	total := size * size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	score += k * 10
+	return score
+}
+
+// linePenalty scores one row or column:
+// runs of 5 or more modules of one colour, and patterns that look like a finder
+func linePenalty(size int, dark func(int) bool) int {
+	score := 0
+	run := 1
+	for i := 1; i <= size; i++ {
+		if i < size && dark(i) == dark(i-1) {
+			run++
+			continue
+		}
+		if run >= 5 {
+			score += 3 + run - 5
+		}
+		run = 1
+	}
+
+	// dark-light-dark-dark-dark-light-dark with four light modules on one side
+	// (outside the code counts as light: it is the quiet zone)
+	finder := []bool{true, false, true, true, true, false, true}
+	for i := 0; i+len(finder) <= size; i++ {
+		matches := true
+		for j, want := range finder {
+			if dark(i+j) != want {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		before, after := true, true
+		for j := 1; j <= 4; j++ {
+			before = before && !darkAt(dark, i-j, size)
+			after = after && !darkAt(dark, i+6+j, size)
+		}
+		if before || after {
+			score += 40
+		}
+	}
+	return score
+}
+
+// darkAt is dark(i), with light outside the code
+func darkAt(dark func(int) bool, i int, size int) bool {
+	return i >= 0 && i < size && dark(i)
+}
+
+// abs is the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
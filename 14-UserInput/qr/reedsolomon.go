@@ -0,0 +1,72 @@
+package qr
+
+// =====================
+// REED–SOLOMON ERROR CORRECTION
+// =====================
+// Codewords are numbers in GF(256), the field of 256 elements QR codes use
+// (reduction polynomial x^8 + x^4 + x^3 + x^2 + 1 = 0x11D)
+// The error correction codewords are the remainder of dividing the data by a
+// generator polynomial, so a reader can find and fix damaged codewords
+
+// exp and log tables: exp[i] = 2^i, log[exp[i]] = i
+var exp, log = fieldTables()
+
+func fieldTables() ([512]byte, [256]byte) {
+	var exp [512]byte
+	var log [256]byte
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+		x <<= 1
+		if x >= 256 {
+			x ^= 0x11D
+		}
+	}
+	// A second copy, so multiply never has to take the sum modulo 255
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}
+
+// multiply multiplies two field elements
+func multiply(a byte, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return exp[int(log[a])+int(log[b])]
+}
+
+// generator returns the polynomial (x - 2^0)(x - 2^1)...(x - 2^(degree-1))
+// Coefficients from the highest power down, without the leading 1
+func generator(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1 // Start with the polynomial 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		// Multiply by (x - root); minus is plus (xor) in GF(256)
+		for j := range result {
+			result[j] = multiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = multiply(root, 2)
+	}
+	return result
+}
+
+// remainder returns the error correction codewords for one block of data
+func remainder(data []byte, generator []byte) []byte {
+	result := make([]byte, len(generator))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range generator {
+			result[i] ^= multiply(coefficient, factor)
+		}
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// =====================
+// OUTPUT: PNG, SVG AND TERMINAL
+// =====================
+
+// QuietZone is the light border readers need around the code, in modules
+const QuietZone = 4
+
+// Image draws the code with scale pixels per module, black on white
+func (code *Code) Image(scale int) image.Image {
+	scale = max(scale, 1)
+	width := (code.Size + 2*QuietZone) * scale
+	palette := color.Palette{color.White, color.Black}
+	img := image.NewPaletted(image.Rect(0, 0, width, width), palette) // Index 0 = white
+	for y := 0; y < width; y++ {
+		for x := 0; x < width; x++ {
+			if code.Dark(x/scale-QuietZone, y/scale-QuietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG writes the code as a PNG image
+func (code *Code) WritePNG(w io.Writer, scale int) error {
+	return png.Encode(w, code.Image(scale))
+}
+
+// SVG returns the code as an SVG image; every module is one unit wide,
+// so it scales to any size without blurring (width and height are in pixels)
+func (code *Code) SVG(pixels int) string {
+	width := code.Size + 2*QuietZone
+	var path strings.Builder
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if !code.Dark(x, y) {
+				continue
+			}
+			// One rectangle per run of dark modules keeps the file small
+			run := 1
+			for code.Dark(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", x+QuietZone, y+QuietZone, run, run)
+			x += run - 1
+		}
+	}
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n", pixels, pixels, width, width)
+	fmt.Fprintf(&svg, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", width, width)
+	fmt.Fprintf(&svg, `<path d="%s" fill="#000"/>`+"\n", path.String())
+	svg.WriteString("</svg>\n")
+	return svg.String()
+}
+
+// Terminal draws the code with half-block characters: one line of text holds two rows
+// Most terminals are light text on a dark background, so by default the
+// light modules are drawn as blocks; invert is for dark text on a light background
+func (code *Code) Terminal(invert bool) string {
+	// The block characters for (top, bottom) being drawn
+	blocks := map[[2]bool]string{
+		{false, false}: " ",
+		{true, false}:  "▀",
+		{false, true}:  "▄",
+		{true, true}:   "█",
+	}
+	drawn := func(x int, y int) bool {
+		return code.Dark(x, y) == invert
+	}
+
+	var out strings.Builder
+	for y := -QuietZone; y < code.Size+QuietZone; y += 2 {
+		for x := -QuietZone; x < code.Size+QuietZone; x++ {
+			bottom := drawn(x, y+1)
+			if y+1 >= code.Size+QuietZone {
+				bottom = false // Odd number of rows: nothing below the last one
+			}
+			out.WriteString(blocks[[2]bool{drawn(x, y), bottom}])
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// String draws the code for a dark terminal (fmt.Println(code))
+func (code *Code) String() string {
+	return code.Terminal(false)
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// qr.Encode([]byte(text), qr.M) -> smallest code for the text (versions 1-40)
+// code.WritePNG(file, 8)        -> PNG, 8 pixels per module
+// code.SVG(256)                 -> SVG text, 256 pixels wide
+// code.Terminal(false)          -> half-block drawing for a terminal
@@ -0,0 +1,63 @@
+package qr
+
+// =====================
+// VERSION TABLES (ISO/IEC 18004)
+// =====================
+// Index 0 is unused, so the version number is the index
+
+// eccPerBlock is the number of error correction codewords in each block
+var eccPerBlock = [4][41]int{
+	L: {0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	M: {0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	Q: {0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	H: {0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+// blockCount is the number of blocks the codewords are split into
+var blockCount = [4][41]int{
+	L: {0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	M: {0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	Q: {0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	H: {0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// rawModules counts the modules of a version left for data and error correction
+// (everything except finder, timing and alignment patterns, format and version info)
+// Version 1: 21x21 = 441 modules, of which 208 are left
+func rawModules(version int) int {
+	result := (16*version+128)*version + 64 // size*size minus finders, separators, timing and format info
+	if version >= 2 {
+		aligns := version/7 + 2
+		result -= (25*aligns-10)*aligns - 55 // Alignment patterns (the ones on the timing lines overlap it)
+	}
+	if version >= 7 {
+		result -= 36 // Two copies of the 18-bit version info
+	}
+	return result
+}
+
+// totalCodewords is the number of 8-bit codewords that fit in a version
+func totalCodewords(version int) int {
+	return rawModules(version) / 8
+}
+
+// dataCodewords is how many codewords carry data (the rest is error correction)
+func dataCodewords(version int, level Level) int {
+	return totalCodewords(version) - eccPerBlock[level][version]*blockCount[level][version]
+}
+
+// alignmentPositions returns the row/column centers of the alignment patterns
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	count := version/7 + 2
+	size := 4*version + 17
+	step := (version*8 + count*3 + 5) / (count*4 - 4) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, position := count-1, size-7; i >= 1; i, position = i-1, position-step {
+		positions[i] = position
+	}
+	return positions
+}
@@ -0,0 +1,261 @@
+package quick
+
+import (
+	"14-UserInput/structs"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================
+// QUICK ENTRY PARSER
+// =====================
+// Turns one line into a person, with simple rules instead of five prompts:
+//   Add Sara, 34, lives in Amsterdam, email sara@x.io, apartment 20A
+// The line is split on commas (and semicolons); every part is checked
+// against the rules below in order, and the first rule that matches wins
+// The first part is the name unless it is clearly something else, so
+// "Add In Lee" and "Add From Ahmed" are names and not places
+
+// Entry is what the parser understood, ready for structs.NewPerson
+type Entry struct {
+	Name        string
+	Age         int
+	Birthday    string            // "YYYY-MM-DD", empty when not given
+	Information map[string]string // e.g., "email": "sara@x.io"
+	Skipped     []string          // Parts no rule understood (shown in the preview)
+}
+
+// ErrNoName means the line has no part that can be the name
+var ErrNoName = errors.New("quick: no name found (start with it, e.g., \"Add Sara, 34\")")
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()./\-]{5,}[0-9]$`)
+	datePattern  = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
+
+	// "34", "34 years old", "age 34", "aged 34", "34 y/o", "34yo"
+	agePattern = regexp.MustCompile(`(?i)^(?:age[d:]?\s*)?(\d{1,3})(?:\s*(?:years?(?:\s+old)?|y/?o|yrs?))?$`)
+
+	// "lives in Amsterdam", "living in", "based in", "from", "location: ..."
+	placePattern = regexp.MustCompile(`(?i)^(?:lives\s+in|living\s+in|based\s+in|resides\s+in|from|location:?|city:?|in)\s+(.+)$`)
+
+	// "works at Acme", "works for Acme", "employer Acme"
+	workPattern = regexp.MustCompile(`(?i)^(?:works\s+(?:at|for)|working\s+(?:at|for)|employer:?)\s+(.+)$`)
+
+	// "born 1990-04-17", "birthday 1990-04-17"
+	bornPattern = regexp.MustCompile(`(?i)^(?:born(?:\s+on)?|birthday:?|bday:?|birth\s+date:?)\s+(.+)$`)
+
+	// "phone +31 20 123 4567", "mobile: 06 12345678"
+	phoneKeyPattern = regexp.MustCompile(`(?i)^(phone|tel|telephone|mobile|cell|work\s+phone|home\s+phone)[:=]?\s+(.+)$`)
+
+	// "name Sara", "called Sara"
+	namePattern = regexp.MustCompile(`(?i)^(?:name:?|called|named)\s+(.+)$`)
+
+	// Leading verbs that are not part of the name
+	verbPattern = regexp.MustCompile(`(?i)^(?:add|new|create|save)(?:\s+person)?\s+`)
+)
+
+// Parse reads one line
+func Parse(line string) (Entry, error) {
+	entry := Entry{Information: map[string]string{}}
+	line = verbPattern.ReplaceAllString(strings.TrimSpace(line), "")
+
+	parts := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ';' })
+	first := true
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if first {
+			first = false
+			if !entry.applyFirst(part) {
+				entry.Name = part // Anything first that is not clearly something else is the name
+			}
+			continue
+		}
+		if !entry.apply(part) {
+			entry.Skipped = append(entry.Skipped, part)
+		}
+	}
+	if entry.Name == "" {
+		return entry, ErrNoName
+	}
+	if born, err := time.Parse(structs.DateLayout, entry.Birthday); err == nil && entry.Age == 0 {
+		entry.Age = ageOn(born, time.Now()) // "born 1990-04-17" is enough
+	}
+	return entry, nil
+}
+
+// ageOn is how old someone born on born is on day
+func ageOn(born time.Time, day time.Time) int {
+	age := day.Year() - born.Year()
+	if day.Month() < born.Month() || day.Month() == born.Month() && day.Day() < born.Day() {
+		age-- // No birthday yet this year
+	}
+	return age
+}
+
+// applyFirst tries the rules on the first part only when it is clearly not a name:
+// "name Sara", an email, or something with digits (an age, a date, a phone number)
+func (entry *Entry) applyFirst(part string) bool {
+	if !namePattern.MatchString(part) && !emailPattern.MatchString(part) && digitCount(part) == 0 {
+		return false
+	}
+	return entry.apply(part)
+}
+
+// apply tries every rule on one part; false when none matched
+// A second age or birthday is not understood rather than replacing the first
+func (entry *Entry) apply(part string) bool {
+	if match := namePattern.FindStringSubmatch(part); match != nil {
+		entry.Name = strings.TrimSpace(match[1])
+		return true
+	}
+	if match := agePattern.FindStringSubmatch(part); match != nil {
+		age, _ := strconv.Atoi(match[1])
+		if age > 150 || entry.Age != 0 {
+			return false // A number, but not an age (or a second one): better to ask than to guess
+		}
+		entry.Age = age
+		return true
+	}
+	if match := bornPattern.FindStringSubmatch(part); match != nil {
+		if date := datePattern.FindString(match[1]); date != "" && validDate(date) && entry.Birthday == "" {
+			entry.Birthday = date
+			return true
+		}
+	}
+	if datePattern.FindString(part) == part {
+		if !validDate(part) || entry.Birthday != "" {
+			return false // "1990-02-30" is not a phone number either
+		}
+		entry.Birthday = part // A date on its own: what else would it be
+		return true
+	}
+	if email := emailPattern.FindString(part); email != "" {
+		entry.add("email", email)
+		return true
+	}
+	if match := phoneKeyPattern.FindStringSubmatch(part); match != nil && phonePattern.MatchString(match[2]) {
+		key := strings.ToLower(strings.Join(strings.Fields(match[1]), " "))
+		if key == "tel" || key == "telephone" {
+			key = "phone"
+		}
+		entry.add(key, match[2])
+		return true
+	}
+	if phonePattern.MatchString(part) && digitCount(part) >= 7 {
+		entry.add("phone", part)
+		return true
+	}
+	if match := placePattern.FindStringSubmatch(part); match != nil {
+		entry.add("location", strings.TrimSpace(match[1]))
+		return true
+	}
+	if match := workPattern.FindStringSubmatch(part); match != nil {
+		entry.add("work", strings.TrimSpace(match[1]))
+		return true
+	}
+	return entry.keyValue(part)
+}
+
+// keyValue reads "key: value", "key=value" and "key value" (e.g., "apartment 20A")
+// A single word is not enough: there would be no value
+func (entry *Entry) keyValue(part string) bool {
+	if key, value, found := strings.Cut(part, ":"); found && strings.TrimSpace(key) != "" && strings.TrimSpace(value) != "" {
+		entry.add(strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value))
+		return true
+	}
+	if key, value, found := strings.Cut(part, "="); found && strings.TrimSpace(key) != "" && strings.TrimSpace(value) != "" {
+		entry.add(strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value))
+		return true
+	}
+	key, value, found := strings.Cut(part, " ")
+	if !found || entry.Name == "" {
+		return false // Before the name, "Sara Jansen" must not become key "sara"
+	}
+	entry.add(strings.ToLower(key), strings.TrimSpace(value))
+	return true
+}
+
+// add stores information; a second email becomes "email 2"
+func (entry *Entry) add(key string, value string) {
+	name := key
+	for n := 2; entry.Information[name] != ""; n++ {
+		name = key + " " + strconv.Itoa(n)
+	}
+	entry.Information[name] = value
+}
+
+// validDate reports whether text is a real date ("2023-02-30" is not)
+func validDate(text string) bool {
+	_, err := time.Parse(structs.DateLayout, text)
+	return err == nil
+}
+
+// digitCount counts the digits in text
+func digitCount(text string) int {
+	count := 0
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			count++
+		}
+	}
+	return count
+}
+
+// =====================
+// RESULT
+// =====================
+
+// Person builds the person with the usual constructor
+func (entry Entry) Person() structs.Person {
+	person := structs.NewPerson(entry.Name, entry.Age, entry.Information)
+	person.Birthday = entry.Birthday
+	return person
+}
+
+// Preview shows what will be saved, so it can be checked before saving
+func (entry Entry) Preview() string {
+	keys := make([]string, 0, len(entry.Information))
+	width := len("Birthday:")
+	for key := range entry.Information {
+		keys = append(keys, key)
+		width = max(width, len(key)+1)
+	}
+	sort.Strings(keys)
+
+	var builder strings.Builder
+	line := func(label string, value string) {
+		fmt.Fprintf(&builder, "  %-*s %s\n", width, label+":", value)
+	}
+	line("Name", entry.Name)
+	if entry.Age > 0 {
+		line("Age", strconv.Itoa(entry.Age))
+	} else {
+		line("Age", "(not given)")
+	}
+	if entry.Birthday != "" {
+		line("Birthday", entry.Birthday)
+	}
+	for _, key := range keys {
+		line(key, entry.Information[key])
+	}
+	for _, skipped := range entry.Skipped {
+		fmt.Fprintf(&builder, "  Not understood: %q\n", skipped)
+	}
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// quick.Parse("Add Sara, 34, lives in Amsterdam") -> Entry{Name: "Sara", Age: 34, ...}
+// entry.Preview()                                -> text to confirm before saving
+// entry.Person()                                 -> structs.Person via NewPerson
@@ -0,0 +1,241 @@
+package stats
+
+import (
+	"14-UserInput/structs"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// =====================
+// REPORT TYPES
+// =====================
+
+// Options controls how the report is built
+type Options struct {
+	BucketSize  int       // Width of each age bucket in the histogram (e.g., 10 -> 20-29)
+	Percentiles []float64 // Percentiles to compute, between 0 and 100
+	Top         int       // How many Information keys/values to list
+	BarWidth    int       // Width in characters of the longest histogram bar
+}
+
+// DefaultOptions returns sensible report settings
+func DefaultOptions() Options {
+	return Options{
+		BucketSize:  10,
+		Percentiles: []float64{25, 50, 75, 90},
+		Top:         5,
+		BarWidth:    40,
+	}
+}
+
+// Percentile is one computed percentile (e.g., P90 = 61.5)
+type Percentile struct {
+	Rank  float64
+	Value float64
+}
+
+// Bucket is one bar of the age histogram: ages From..To (inclusive)
+type Bucket struct {
+	From  int
+	To    int
+	Count int
+}
+
+// Usage counts how often a key or value appears
+type Usage struct {
+	Name  string
+	Count int
+}
+
+// Report holds the statistics for a list of people
+type Report struct {
+	Count       int
+	MeanAge     float64
+	MedianAge   float64
+	MinAge      int
+	MaxAge      int
+	Percentiles []Percentile
+	Histogram   []Bucket
+	TopKeys     []Usage
+	TopValues   []Usage
+	barWidth    int
+}
+
+// =====================
+// BUILDING THE REPORT
+// =====================
+
+// NewReport calculates all statistics for the given people
+// An empty list gives a report with Count 0 (no division by zero)
+func NewReport(people []structs.Person, options Options) Report {
+	if options.BucketSize <= 0 {
+		options.BucketSize = DefaultOptions().BucketSize
+	}
+	if options.BarWidth <= 0 {
+		options.BarWidth = DefaultOptions().BarWidth
+	}
+
+	report := Report{Count: len(people), barWidth: options.BarWidth}
+	if len(people) == 0 {
+		return report
+	}
+
+	// Copy the ages into a sorted slice: median and percentiles need order
+	ages := make([]int, 0, len(people))
+	total := 0
+	for _, person := range people {
+		ages = append(ages, person.Age)
+		total += person.Age
+	}
+	sort.Ints(ages)
+
+	report.MeanAge = float64(total) / float64(len(ages))
+	report.MedianAge = percentile(ages, 50)
+	report.MinAge = ages[0]
+	report.MaxAge = ages[len(ages)-1]
+
+	for _, rank := range options.Percentiles {
+		report.Percentiles = append(report.Percentiles, Percentile{Rank: rank, Value: percentile(ages, rank)})
+	}
+
+	report.Histogram = histogram(ages, options.BucketSize)
+	report.TopKeys, report.TopValues = informationUsage(people, options.Top)
+	return report
+}
+
+// percentile returns the p-th percentile of sorted ages
+// It interpolates linearly between the two closest ranks
+func percentile(sortedAges []int, p float64) float64 {
+	p = math.Max(0, math.Min(100, p))
+	position := p / 100 * float64(len(sortedAges)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	fraction := position - float64(lower)
+	return float64(sortedAges[lower]) + fraction*float64(sortedAges[upper]-sortedAges[lower])
+}
+
+// histogram groups sorted ages into buckets of bucketSize years
+// Buckets without people in between are kept so the chart has no gaps
+func histogram(sortedAges []int, bucketSize int) []Bucket {
+	first := floorDiv(sortedAges[0], bucketSize) * bucketSize
+	last := floorDiv(sortedAges[len(sortedAges)-1], bucketSize) * bucketSize
+
+	buckets := []Bucket{}
+	for from := first; from <= last; from += bucketSize {
+		buckets = append(buckets, Bucket{From: from, To: from + bucketSize - 1})
+	}
+	for _, age := range sortedAges {
+		index := (floorDiv(age, bucketSize)*bucketSize - first) / bucketSize
+		buckets[index].Count++
+	}
+	return buckets
+}
+
+// floorDiv divides and rounds towards negative infinity (so -1 lands in -10..-1)
+func floorDiv(a, b int) int {
+	quotient := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		quotient--
+	}
+	return quotient
+}
+
+// informationUsage counts the Information keys and "key: value" pairs
+// and returns the top most used of each
+func informationUsage(people []structs.Person, top int) ([]Usage, []Usage) {
+	keyCounts := map[string]int{}
+	valueCounts := map[string]int{}
+	for _, person := range people {
+		for key, value := range person.Information {
+			keyCounts[key]++
+			valueCounts[key+": "+value]++
+		}
+	}
+	return topUsage(keyCounts, top), topUsage(valueCounts, top)
+}
+
+// topUsage sorts counts from most to least used (ties alphabetically)
+func topUsage(counts map[string]int, top int) []Usage {
+	usages := make([]Usage, 0, len(counts))
+	for name, count := range counts {
+		usages = append(usages, Usage{Name: name, Count: count})
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Count != usages[j].Count {
+			return usages[i].Count > usages[j].Count
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	if top > 0 && len(usages) > top {
+		usages = usages[:top]
+	}
+	return usages
+}
+
+// =====================
+// FORMATTING
+// =====================
+
+// Format returns the report as text with an ASCII bar chart
+func (report Report) Format() string {
+	if report.Count == 0 {
+		return "No people saved yet."
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "People: %d\n", report.Count)
+	fmt.Fprintf(&builder, "Age mean: %.2f, median: %.1f, min: %d, max: %d\n",
+		report.MeanAge, report.MedianAge, report.MinAge, report.MaxAge)
+
+	for _, p := range report.Percentiles {
+		fmt.Fprintf(&builder, "  P%g: %.1f\n", p.Rank, p.Value)
+	}
+
+	builder.WriteString("\nAge histogram:\n")
+	builder.WriteString(report.formatHistogram())
+
+	builder.WriteString("\nMost used information keys:\n")
+	writeUsage(&builder, report.TopKeys)
+	builder.WriteString("\nMost used information values:\n")
+	writeUsage(&builder, report.TopValues)
+
+	return strings.TrimRight(builder.String(), "\n")
+}
+
+// formatHistogram draws one '#' bar per bucket, scaled to the widest bucket
+func (report Report) formatHistogram() string {
+	largest := 0
+	for _, bucket := range report.Histogram {
+		largest = max(largest, bucket.Count)
+	}
+
+	var builder strings.Builder
+	for _, bucket := range report.Histogram {
+		bar := 0
+		if largest > 0 {
+			bar = int(math.Round(float64(bucket.Count) / float64(largest) * float64(report.barWidth)))
+		}
+		label := fmt.Sprintf("%d-%d", bucket.From, bucket.To)
+		fmt.Fprintf(&builder, "  %9s | %-*s %d\n", label, report.barWidth, strings.Repeat("#", bar), bucket.Count)
+	}
+	return builder.String()
+}
+
+// writeUsage prints one "name (count)" line per usage
+func writeUsage(builder *strings.Builder, usages []Usage) {
+	if len(usages) == 0 {
+		builder.WriteString("  (none)\n")
+		return
+	}
+	for _, usage := range usages {
+		fmt.Fprintf(builder, "  %-30s %d\n", usage.Name, usage.Count)
+	}
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// stats.NewReport(people, stats.DefaultOptions()) -> compute statistics
+// report.Format()                                 -> text report with bar chart
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"14-UserInput/structs"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// =====================
+// PERSON STORE
+// =====================
+
+// DefaultPath is the file used when no other path is given
+const DefaultPath = "people.json"
+
+// Store keeps every saved Person and the file they live in
+type Store struct {
+	path   string           // Where the people are saved
+	People []structs.Person // All saved people, in the order they were added
+}
+
+// =====================
+// CONSTRUCTOR FUNCTION
+// =====================
+
+// Open loads the store from a JSON file
+// A missing file is not an error: you simply get an empty store
+// People saved before UIDs existed get a random one, and the file is saved
+// once right away, so the UID never changes again (calendar subscriptions and
+// address books must not see a new UID on every load)
+func Open(path string) (*Store, error) {
+	store := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil // Nothing saved yet
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &store.People); err != nil {
+		return nil, err
+	}
+
+	migrated := false
+	for i := range store.People {
+		if store.People[i].UID == "" {
+			store.People[i].UID = NewUID()
+			migrated = true
+		}
+	}
+	if migrated {
+		if err := store.Save(); err != nil {
+			return nil, fmt.Errorf("saving new UIDs: %w", err)
+		}
+	}
+	return store, nil
+}
+
+// NewUID returns a random ID for a person, e.g., "3f2a9c04-71be-4d0e-9a55-0c6e2f1b8d47"
+func NewUID() string {
+	b := make([]byte, 16)
+	rand.Read(b) // Never fails (see crypto/rand)
+	return formatUUID(b)
+}
+
+// formatUUID marks 16 bytes as a version 4 UUID and writes them in the usual groups
+func formatUUID(b []byte) string {
+	b[6] = b[6]&0x0f | 0x40 // Version 4 (random) UUID
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// =====================
+// RECEIVER FUNCTIONS (METHODS)
+// =====================
+
+// Path returns the file the store saves to
+func (store *Store) Path() string {
+	return store.path
+}
+
+// Add appends a person to the store (call Save to write it to disk)
+// A person without a UID gets one
+func (store *Store) Add(person structs.Person) {
+	if person.UID == "" {
+		person.UID = NewUID()
+	}
+	store.People = append(store.People, person)
+}
+
+// Find returns the index of the person with a UID
+func (store *Store) Find(uid string) (int, bool) {
+	for i, person := range store.People {
+		if person.UID == uid {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Put replaces the person with the same UID, or adds them when the UID is new
+func (store *Store) Put(person structs.Person) {
+	if i, ok := store.Find(person.UID); ok && person.UID != "" {
+		store.People[i] = person
+		return
+	}
+	store.Add(person)
+}
+
+// Remove deletes the person with a UID; false when there is none
+func (store *Store) Remove(uid string) bool {
+	i, ok := store.Find(uid)
+	if !ok {
+		return false
+	}
+	store.People = append(store.People[:i], store.People[i+1:]...)
+	return true
+}
+
+// Save writes every person to the store file as indented JSON
+// The data goes to a temporary file first, so a crash never leaves half a file behind
+func (store *Store) Save() error {
+	data, err := json.MarshalIndent(store.People, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpPath := store.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, store.path)
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// store.Open(path)        -> load people from a JSON file
+// store.Add(person)       -> add a person in memory
+// store.Put / store.Remove -> replace or delete a person by UID
+// store.Save()            -> write all people back to disk
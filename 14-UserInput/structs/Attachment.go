@@ -0,0 +1,68 @@
+package structs
+
+import "fmt"
+
+// =====================
+// ATTACHMENTS
+// =====================
+
+// Attachment points to a file kept in the blob store (see the blobs package)
+// The person only keeps the hash; the content lives in the store
+type Attachment struct {
+	Name string // Unique per person, e.g., "avatar.jpg"
+	Hash string // SHA-256 of the content, in hex
+	MIME string // e.g., "image/jpeg"
+	Size int64  // In bytes
+}
+
+// Attachment returns the attachment with a name
+func (person *Person) Attachment(name string) (Attachment, bool) {
+	for _, attachment := range person.Attachments {
+		if attachment.Name == name {
+			return attachment, true
+		}
+	}
+	return Attachment{}, false
+}
+
+// Attach adds an attachment, replacing the one with the same name
+func (person *Person) Attach(attachment Attachment) {
+	for i := range person.Attachments {
+		if person.Attachments[i].Name == attachment.Name {
+			person.Attachments[i] = attachment
+			return
+		}
+	}
+	person.Attachments = append(person.Attachments, attachment)
+}
+
+// Detach removes the attachment with a name; false when there is none
+func (person *Person) Detach(name string) bool {
+	for i, attachment := range person.Attachments {
+		if attachment.Name == name {
+			person.Attachments = append(person.Attachments[:i], person.Attachments[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the attachment: "avatar.jpg (image/jpeg, 48.2 KB)"
+func (attachment Attachment) String() string {
+	return fmt.Sprintf("%s (%s, %s)", attachment.Name, attachment.MIME, FormatSize(attachment.Size))
+}
+
+// FormatSize prints a byte count for people: 512 B, 48.2 KB, 3.1 MB
+func FormatSize(size int64) string {
+	units := []string{"B", "KB", "MB", "GB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
@@ -3,6 +3,7 @@ package structs
 import (
     "sort"
     "strconv"
+    "time"
 )
 
 // =====================
@@ -11,9 +12,12 @@ import (
 
 // Person holds basic info and extra details in a map
 type Person struct {
+    UID         string            `json:",omitempty"` // Stable ID, given by the store (e.g., for CardDAV)
     Name        string            // Person's name
     Age         int               // Person's age
+    Birthday    string            `json:",omitempty"` // Birth date as "YYYY-MM-DD" (optional)
     Information map[string]string // Extra info (e.g., "email": "test@test.com")
+    Attachments []Attachment      `json:",omitempty"` // Files such as an avatar or an ID scan
 }
 
 // =====================
@@ -41,12 +45,21 @@ func NewPerson(name string, age int, information map[string]string) Person {
 func (person *Person) PersonFormattedInformation() string {
     // strconv.Itoa converts int to string
     personFormatedString := "Person name: " + person.Name +
-        ", Age: " + strconv.Itoa(person.Age) + "\nDetailed Info:"
+        ", Age: " + strconv.Itoa(person.Age)
+    if person.Birthday != "" {
+        personFormatedString += ", Birthday: " + person.Birthday
+    }
+    personFormatedString += "\nDetailed Info:"
 
     // Loop through all extra information
     for key, value := range person.Information {
         personFormatedString += "\n" + key + ": " + value
     }
+
+    // Attachments are listed by name, type and size (String method in Attachment.go)
+    for _, attachment := range person.Attachments {
+        personFormatedString += "\nAttachment: " + attachment.String()
+    }
     return personFormatedString
 }
 
@@ -63,6 +76,16 @@ func (person *Person) AddExtraInformation(infoType string, details string) {
     person.sortInformation() // Keep info sorted
 }
 
+// BirthDate returns the parsed Birthday
+// false when there is no birthday (or it is not a valid date)
+func (person *Person) BirthDate() (time.Time, bool) {
+    date, err := time.Parse(DateLayout, person.Birthday)
+    return date, err == nil
+}
+
+// DateLayout is how birthdays are written (Go's reference date)
+const DateLayout = "2006-01-02"
+
 // =====================
 // PRIVATE METHOD
 // =====================
@@ -96,4 +119,5 @@ func (person *Person) sortInformation() {
 // func (p Person) Method()   -> value receiver (works on a copy)
 // func (p *Person) Method()  -> pointer receiver (modifies original)
 // Lowercase method name      -> private (only this package can use it)
-// Uppercase method name      -> public (other packages can use it)
\ No newline at end of file
+// Uppercase method name      -> public (other packages can use it)
+// time.Parse("2006-01-02", s) -> parse a date like "1990-04-17"
\ No newline at end of file
@@ -1,7 +1,74 @@
 package main
 
-import "fmt"
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
 
 func main() {
-	fmt.Println("Hello, World!")
-}
\ No newline at end of file
+	// =====================
+	// SWITCH STATEMENT
+	// =====================
+
+	// The restaurant menu from 09-Maps
+	menu := map[string]float64{
+		"soup": 4.18,
+		"rice": 1.98,
+	}
+
+	// What has been ordered so far: product name -> quantity
+	order := map[string]int{}
+
+	reader := bufio.NewReader(os.Stdin)
+
+	// Keep asking until the user chooses to stop
+	for {
+		fmt.Print("\nChoose option (a - add item, r - remove item, v - view order, q - quit): ")
+		option, err := reader.ReadString('\n')
+		if err != nil {
+			return // No more input
+		}
+		option = strings.TrimSpace(option)
+
+		// switch compares option with every case, top to bottom
+		// Only the first matching case runs (no "break" needed in Go)
+		switch option {
+		case "a":
+			name := ask("Item name: ", reader)
+			if _, ok := menu[name]; !ok {
+				fmt.Println(name, "is not on the menu")
+				continue
+			}
+			order[name]++
+		case "r":
+			name := ask("Item name: ", reader)
+			delete(order, name) // Deleting a missing key does nothing
+		case "v":
+			for name, quantity := range order {
+				fmt.Printf("%d x %s\n", quantity, name)
+			}
+		case "q", "quit": // One case can match several values
+			return
+		default: // Runs when no other case matched
+			fmt.Println("That was not a valid option...")
+		}
+	}
+}
+
+// ask prints a question and returns the trimmed answer
+func ask(question string, reader *bufio.Reader) string {
+	fmt.Print(question)
+	answer, _ := reader.ReadString('\n')
+	return strings.TrimSpace(answer)
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// switch value { case "a": ... }  -> run the matching case
+// case "q", "quit":               -> match several values
+// default:                        -> runs when nothing matched
+// switch { case x > 10: ... }     -> switch without a value works like if/else
+// The full bill app lives in 17-SavingFiles
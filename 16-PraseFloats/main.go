@@ -1,7 +1,57 @@
 package main
 
-import "fmt"
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
 
 func main() {
-	fmt.Println("Hello, World!")
-}
\ No newline at end of file
+	// =====================
+	// PARSING FLOATS
+	// =====================
+
+	reader := bufio.NewReader(os.Stdin)
+
+	// Input from the keyboard is always a string
+	// strconv.ParseFloat turns "2.5" into the number 2.5
+	// The 64 means "give me a float64"
+	fmt.Print("Enter tip amount ($): ")
+	tipStr, _ := reader.ReadString('\n')
+	tipStr = strings.TrimSpace(tipStr)
+
+	tip, err := strconv.ParseFloat(tipStr, 64)
+	if err != nil {
+		// err explains what went wrong (e.g., parsing "abc": invalid syntax)
+		fmt.Println("The tip must be a number:", err)
+		return
+	}
+
+	// Whole numbers use strconv.Atoi instead
+	fmt.Print("Number of diners: ")
+	dinersStr, _ := reader.ReadString('\n')
+	diners, err := strconv.Atoi(strings.TrimSpace(dinersStr))
+	if err != nil || diners <= 0 {
+		fmt.Println("The number of diners must be a whole number above 0")
+		return
+	}
+
+	// Split the tip between the diners
+	// %.2f prints a float with 2 decimals
+	subtotal := 4.18 + 1.98 // soup + rice from 09-Maps
+	total := subtotal + tip
+	fmt.Printf("Total: $%.2f, each diner pays $%.2f\n", total, total/float64(diners))
+
+	// FormatFloat goes the other way: number -> string
+	fmt.Println("As text: " + strconv.FormatFloat(total, 'f', 2, 64))
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// strconv.ParseFloat(s, 64)            -> string to float64 (returns an error for "abc")
+// strconv.Atoi(s)                      -> string to int
+// strconv.FormatFloat(f, 'f', 2, 64)   -> float64 to string with 2 decimals
+// The full bill app lives in 17-SavingFiles
@@ -0,0 +1,533 @@
+package bill
+
+import (
+	"17-SavingFiles/inventory"
+	"17-SavingFiles/menu"
+	"17-SavingFiles/money"
+	"17-SavingFiles/promo"
+	"17-SavingFiles/tax"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// =====================
+// STRUCT DEFINITION
+// =====================
+
+// Item is one line on the bill
+type Item struct {
+	Name      string      // Menu product
+	Modifiers []string    // Chosen options, in menu order (e.g., "large", "no onions")
+	Combo     []string    // Parts, when the product is a combo
+	Price     money.Money // Price of one item, options included
+	Quantity  int
+	TaxClass  string
+}
+
+// Label tells lines of the same product apart: "rice (large, no onions)"
+func (item Item) Label() string {
+	return menu.Selection{Product: item.Name, Modifiers: item.Modifiers}.Label()
+}
+
+// parts is what the kitchen makes for one item: the combo parts, or the item itself
+func (item Item) parts() []string {
+	if len(item.Combo) > 0 {
+		return item.Combo
+	}
+	return []string{item.Name}
+}
+
+// sameLine reports whether two items go on one line: same product, options and price
+func (item Item) sameLine(other Item) bool {
+	return item.Label() == other.Label() && item.Price.Cmp(other.Price) == 0 && item.TaxClass == other.TaxClass
+}
+
+// Total is the price of the whole line (price x quantity)
+// AddSelection refuses quantities whose total does not fit, so this cannot overflow
+func (item Item) Total() money.Money {
+	total, err := item.Price.Mul(int64(item.Quantity))
+	if err != nil {
+		panic(err)
+	}
+	return total
+}
+
+// Bill holds everything ordered at one table
+type Bill struct {
+	Name     string
+	Currency string    // Every amount on the bill is in this currency
+	OpenedAt time.Time // When the bill was created (prices are those of this moment)
+	Items    []Item    // In the order they were first added
+	Tip      money.Money
+
+	taxRules *tax.Jurisdiction // nil = no tax on this bill
+	taxMode  tax.Mode
+
+	stock *inventory.Inventory // nil = stock is not tracked
+
+	promotions     *promo.Engine    // nil = no promotions
+	fixedDiscounts []promo.Applied  // Discounts of a reprinted order, used instead of promotions
+	coupons        []string         // Coupon codes given by the customer
+	clock          func() time.Time // When the order is placed (happy hours)
+}
+
+// =====================
+// CONSTRUCTOR FUNCTION
+// =====================
+
+// NewBill creates an empty bill with no tip
+func NewBill(name string, currency string) Bill {
+	return Bill{
+		Name:     name,
+		Currency: currency,
+		OpenedAt: time.Now(),
+		Items:    []Item{},
+		Tip:      money.Zero(currency),
+	}
+}
+
+// =====================
+// CHANGING THE BILL
+// =====================
+
+// AddItem adds quantity of a menu product to the bill, with the default options
+// Adding a product that is already on the bill increases its quantity
+func (bill *Bill) AddItem(fromMenu menu.Menu, name string, quantity int) error {
+	return bill.AddSelection(fromMenu, menu.Selection{Product: name}, quantity)
+}
+
+// AddSelection adds quantity of a product with options, e.g., "rice, large, no onions"
+// The same product with other options gets its own line, priced with its options
+// So does the same product at another price (the menu changed in between):
+// every line keeps the price it was charged at
+func (bill *Bill) AddSelection(fromMenu menu.Menu, selection menu.Selection, quantity int) error {
+	selection, price, err := fromMenu.Resolve(selection)
+	if err != nil {
+		return err
+	}
+	name := selection.Product
+	product := fromMenu[name]
+	if product.SoldOut {
+		return fmt.Errorf("%s is sold out", name)
+	}
+	if quantity <= 0 {
+		return errors.New("quantity must be at least 1")
+	}
+	if price.Currency() != bill.Currency {
+		return fmt.Errorf("%s is priced in %s, the bill is in %s", name, price.Currency(), bill.Currency)
+	}
+	if bill.taxRules != nil {
+		if _, err := bill.taxRules.RateFor(product.TaxClass); err != nil {
+			return err
+		}
+	}
+
+	item := Item{Name: name, Modifiers: selection.Modifiers, Combo: product.Combo, Price: price, Quantity: quantity, TaxClass: product.TaxClass}
+	total := quantity
+	for _, existing := range bill.Items {
+		if existing.sameLine(item) {
+			total += existing.Quantity
+		}
+	}
+	if _, err := price.Mul(int64(total)); err != nil {
+		return err
+	}
+	if err := bill.consume(item.parts(), quantity); err != nil {
+		return err
+	}
+
+	for i := range bill.Items {
+		if bill.Items[i].sameLine(item) {
+			bill.Items[i].Quantity += quantity
+			return nil
+		}
+	}
+	bill.Items = append(bill.Items, item)
+	return nil
+}
+
+// consume takes quantity of every part from the inventory (if stock is tracked)
+// Fails with inventory.ErrOutOfStock when the kitchen cannot make it; then nothing is taken
+func (bill *Bill) consume(parts []string, quantity int) error {
+	if bill.stock == nil {
+		return nil
+	}
+	for i, part := range parts {
+		if err := bill.stock.Consume(part, quantity); err != nil {
+			for _, taken := range parts[:i] {
+				bill.stock.Return(taken, quantity)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveItem takes quantity of a line off the bill
+// name is the line's label ("rice (large)"), or the product when it is on one line only
+// A quantity of 0 (or more than ordered) removes the whole line
+func (bill *Bill) RemoveItem(name string, quantity int) error {
+	i, err := bill.find(name)
+	if err != nil {
+		return err
+	}
+	item := bill.Items[i]
+	if quantity <= 0 || quantity >= item.Quantity {
+		quantity = item.Quantity
+		bill.Items = append(bill.Items[:i], bill.Items[i+1:]...)
+	} else {
+		bill.Items[i].Quantity -= quantity
+	}
+	if bill.stock != nil {
+		for _, part := range item.parts() {
+			bill.stock.Return(part, quantity) // Not served, so back on the shelf
+		}
+	}
+	return nil
+}
+
+// find returns the index of the line with that name (see lineName),
+// or of the only line with that label or product
+func (bill *Bill) find(name string) (int, error) {
+	found := []int{}
+	for i, item := range bill.Items {
+		if bill.lineName(i) == name {
+			return i, nil
+		}
+		if item.Label() == name || item.Name == name {
+			found = append(found, i)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return 0, fmt.Errorf("%q is not on the bill", name)
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, len(found))
+	for i, index := range found {
+		names[i] = bill.lineName(index)
+	}
+	return 0, fmt.Errorf("%q is on more than one line, use one of: %s", name, strings.Join(names, "; "))
+}
+
+// lineName names line i so that no other line has the same name; promotions, tax and
+// RemoveItem use it to tell lines apart. It is the label ("soup"), with the price when the
+// product is on the bill at two prices ("soup @ $4.18"), and the line number when even
+// that is not enough (same price, other tax class: "soup @ $4.18 #2")
+func (bill *Bill) lineName(i int) string {
+	item := bill.Items[i]
+	sameLabel, samePrice := false, false
+	for j, other := range bill.Items {
+		if j == i || other.Label() != item.Label() {
+			continue
+		}
+		sameLabel = true
+		if other.Price.Cmp(item.Price) == 0 {
+			samePrice = true
+		}
+	}
+	name := item.Label()
+	if sameLabel {
+		name += " @ " + item.Price.String()
+	}
+	if samePrice {
+		name += fmt.Sprintf(" #%d", i+1)
+	}
+	return name
+}
+
+// UpdateTip sets the tip (it replaces the previous tip)
+func (bill *Bill) UpdateTip(tip money.Money) error {
+	if tip.IsNegative() {
+		return errors.New("tip cannot be negative")
+	}
+	if tip.Currency() != bill.Currency {
+		return fmt.Errorf("tip must be in %s", bill.Currency)
+	}
+	bill.Tip = tip
+	return nil
+}
+
+// SetTax makes the bill charge tax under a jurisdiction's rules
+// It fails if an item already on the bill has no rate there
+func (bill *Bill) SetTax(jurisdiction tax.Jurisdiction, mode tax.Mode) error {
+	for _, item := range bill.Items {
+		if _, err := jurisdiction.RateFor(item.TaxClass); err != nil {
+			return fmt.Errorf("%s: %w", item.Label(), err)
+		}
+	}
+	bill.taxRules = &jurisdiction
+	bill.taxMode = mode
+	return nil
+}
+
+// SetInventory makes ordering take stock from the inventory
+// Ordering an item then fails when there is not enough stock
+func (bill *Bill) SetInventory(stock *inventory.Inventory) {
+	bill.stock = stock
+}
+
+// ReleaseStock puts everything on the bill back on the shelf and stops taking stock
+// Use it when the bill is abandoned without being saved
+func (bill *Bill) ReleaseStock() {
+	if bill.stock == nil {
+		return
+	}
+	for _, item := range bill.Items {
+		for _, part := range item.parts() {
+			bill.stock.Return(part, item.Quantity)
+		}
+	}
+	bill.stock = nil
+}
+
+// SetPromotions makes the bill pick the best promotions from the engine
+// clock tells the engine what time it is (nil = time.Now)
+func (bill *Bill) SetPromotions(engine *promo.Engine, clock func() time.Time) {
+	if clock == nil {
+		clock = time.Now
+	}
+	bill.promotions = engine
+	bill.clock = clock
+}
+
+// AddCoupon records a coupon code given by the customer
+func (bill *Bill) AddCoupon(code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return errors.New("coupon code is empty")
+	}
+	bill.coupons = append(bill.coupons, code)
+	return nil
+}
+
+// =====================
+// TOTALS
+// =====================
+
+// Subtotal is the price of all items as on the menu, without tip or added tax
+func (bill *Bill) Subtotal() money.Money {
+	total := money.Zero(bill.Currency)
+	for _, item := range bill.Items {
+		total = total.Add(item.Total())
+	}
+	return total
+}
+
+// Discounts returns the best combination of promotions for the bill as it is now
+// It is recalculated on every call, so adding items can change the choice
+// A reprinted order gives the discounts it was saved with
+func (bill *Bill) Discounts() []promo.Applied {
+	if bill.fixedDiscounts != nil {
+		return bill.fixedDiscounts
+	}
+	if bill.promotions == nil || len(bill.Items) == 0 {
+		return nil
+	}
+
+	order := promo.Order{At: bill.clock(), Codes: bill.coupons}
+	for i, item := range bill.Items {
+		order.Lines = append(order.Lines, promo.Line{Name: bill.lineName(i), Product: item.Name, Price: item.Price, Quantity: item.Quantity})
+	}
+	return bill.promotions.Best(order)
+}
+
+// DiscountTotal is the sum of all discounts
+func (bill *Bill) DiscountTotal() money.Money {
+	total := money.Zero(bill.Currency)
+	for _, discount := range bill.Discounts() {
+		total = total.Add(discount.Amount)
+	}
+	return total
+}
+
+// Tax calculates the tax per line and per rate
+// ok is false when the bill has no tax rules
+func (bill *Bill) Tax() (result tax.Result, ok bool) {
+	if bill.taxRules == nil {
+		return tax.Result{}, false
+	}
+
+	// Tax is charged on what the customer pays, so discounts are taken off each line first
+	discounted := map[string]money.Money{}
+	for _, discount := range bill.Discounts() {
+		for name, amount := range discount.PerItem {
+			discounted[name] = discounted[name].Add(amount)
+		}
+	}
+
+	lines := make([]tax.Line, 0, len(bill.Items))
+	for i, item := range bill.Items {
+		name := bill.lineName(i)
+		lines = append(lines, tax.Line{Name: name, Class: item.TaxClass, Amount: item.Total().Sub(discounted[name])})
+	}
+	// Every tax class was checked in AddItem/SetTax, so this cannot fail
+	result, _ = bill.taxRules.Calculate(lines, bill.taxMode, bill.Currency)
+	return result, true
+}
+
+// AddedTax is the tax added on top of the subtotal
+// It is zero when there are no tax rules or menu prices already include tax
+func (bill *Bill) AddedTax() money.Money {
+	result, ok := bill.Tax()
+	if !ok || bill.taxRules.PricesIncludeTax {
+		return money.Zero(bill.Currency)
+	}
+	return result.Tax
+}
+
+// Total is the subtotal minus discounts, plus added tax and the tip (the tip is never taxed)
+func (bill *Bill) Total() money.Money {
+	return bill.Subtotal().Sub(bill.DiscountTotal()).Add(bill.AddedTax()).Add(bill.Tip)
+}
+
+// Split divides the total between diners
+// The first diners pay the leftover cents, so the shares always add up to the total
+func (bill *Bill) Split(diners int) ([]money.Money, error) {
+	if diners <= 0 {
+		return nil, errors.New("there must be at least one diner")
+	}
+	return bill.Total().Allocate(diners)
+}
+
+// =====================
+// FORMATTING AND SAVING
+// =====================
+
+// Format returns the receipt as text
+func (bill *Bill) Format() string {
+	return bill.FormatWith(nil)
+}
+
+// receiptLine is one row of the receipt: a label, an amount and an optional note below it
+type receiptLine struct {
+	label  string
+	amount money.Money
+	note   string
+}
+
+// FormatWith returns the receipt with every amount also shown in a second currency
+// A nil convert gives the plain receipt
+// A missing or stale rate never stops the receipt: the converted amount shows "n/a"
+// and a warning below the receipt says why
+func (bill *Bill) FormatWith(convert money.Converter) string {
+	lines := []receiptLine{}
+	for _, item := range bill.Items {
+		line := receiptLine{label: fmt.Sprintf("%d x %s:", item.Quantity, item.Label()), amount: item.Total()}
+		if len(item.Combo) > 0 {
+			line.note = "with " + strings.Join(item.Combo, " + ")
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, receiptLine{label: "subtotal:", amount: bill.Subtotal()})
+
+	// Each discount explains itself on the line below
+	for _, discount := range bill.Discounts() {
+		lines = append(lines, receiptLine{label: discount.Name + ":", amount: discount.Amount.Neg(), note: discount.Explanation})
+	}
+
+	taxResult, hasTax := bill.Tax()
+	if hasTax {
+		label := "tax:"
+		if bill.taxRules.PricesIncludeTax {
+			label = "incl. tax:"
+		}
+		lines = append(lines, receiptLine{label: label, amount: taxResult.Tax})
+	}
+
+	lines = append(lines, receiptLine{label: "tip:", amount: bill.Tip}, receiptLine{label: "total:", amount: bill.Total()})
+
+	width := 25 // Long labels ("rice (extra large, no onions)") widen the column
+	for _, line := range lines {
+		width = max(width, len(line.label))
+	}
+
+	formatted := "Bill breakdown: " + bill.Name + "\n"
+	warnings := []string{}
+	for _, line := range lines {
+		text, err := formatAmount(line.amount, convert)
+		if err != nil && !slices.Contains(warnings, err.Error()) {
+			warnings = append(warnings, err.Error())
+		}
+		formatted += fmt.Sprintf("%-*s %s\n", width, line.label, text)
+		if line.note != "" {
+			formatted += "    " + line.note + "\n"
+		}
+	}
+
+	if hasTax {
+		formatted += "\nTax breakdown (" + bill.taxRules.Name + "):\n"
+		for _, total := range taxResult.ByRate {
+			formatted += fmt.Sprintf("  %-8s net %-10s tax %-10s gross %s\n", total.Rate, total.Net, total.Tax, total.Gross)
+		}
+	}
+	for _, warning := range warnings {
+		formatted += "\nWarning: converted amounts shown as n/a (" + warning + ")\n"
+	}
+	return formatted
+}
+
+// formatAmount prints an amount, followed by "(converted)" when convert is set
+// When converting fails the amount is still printed, with "(n/a)", and the error returned
+func formatAmount(amount money.Money, convert money.Converter) (string, error) {
+	if convert == nil {
+		return amount.String(), nil
+	}
+	converted, err := convert(amount)
+	if err != nil {
+		return fmt.Sprintf("%-12s (n/a)", amount), err
+	}
+	return fmt.Sprintf("%-12s (%s)", amount, converted), nil
+}
+
+// Save writes the receipt to <dir>/<bill name>.txt and returns the file path
+// The order itself is saved next to it as <bill name>.json so it can be reprinted
+// A non-nil convert adds the second-currency column
+func (bill *Bill) Save(dir string, convert money.Converter) (string, error) {
+	formatted := bill.FormatWith(convert)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fileName(bill.Name)+".txt")
+	data := []byte(formatted) // Files are written as bytes
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	if err := bill.SaveOrder(filepath.Join(dir, fileName(bill.Name)+".json")); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// fileName keeps letters, digits, '-' and '_' so any bill name is a safe file name
+func fileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r == ' ':
+			return '_'
+		}
+		return -1
+	}, name)
+	if safe == "" {
+		return "bill"
+	}
+	return safe
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// bill.NewBill(name, "USD")     -> empty bill
+// b.AddItem(menu, "soup", 2)    -> order from the menu
+// b.AddSelection(menu, menu.ParseSelection("rice, large"), 1) -> with options
+// b.Split(3)                    -> what each diner pays
+// b.Save("bills", nil)          -> os.WriteFile the receipt
@@ -0,0 +1,207 @@
+package bill
+
+import (
+	"17-SavingFiles/menu"
+	"17-SavingFiles/money"
+	"17-SavingFiles/promo"
+	"17-SavingFiles/tax"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// =====================
+// SAVED ORDERS
+// =====================
+
+// OrderLine is one product, its options, how many were ordered and what one cost
+type OrderLine struct {
+	Name      string      `json:"name"`
+	Modifiers []string    `json:"modifiers,omitempty"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"` // Charged for one, options included
+	TaxClass  string      `json:"tax_class,omitempty"`
+	Combo     []string    `json:"combo,omitempty"`
+}
+
+// Order is what was ordered and the prices charged for it,
+// so an old receipt can be reprinted exactly as it was
+type Order struct {
+	Name     string      `json:"name"`
+	Currency string      `json:"currency"`
+	OpenedAt time.Time   `json:"opened_at"`
+	Lines    []OrderLine `json:"lines"`
+	Tip      money.Money `json:"tip"`
+	Coupons  []string    `json:"coupons,omitempty"`
+	Charged  *Charged    `json:"charged,omitempty"` // nil in orders saved before it was stored
+}
+
+// Charged is what the bill took off and added at the time: the discounts given
+// and the tax rules used, so a reprint does not depend on today's rule files
+type Charged struct {
+	Discounts []OrderDiscount `json:"discounts"`
+	Tax       *OrderTax       `json:"tax,omitempty"` // nil = no tax was charged
+}
+
+// OrderDiscount is one promotion as it was given
+type OrderDiscount struct {
+	Name        string                 `json:"name"`
+	Amount      money.Money            `json:"amount"`
+	Explanation string                 `json:"explanation"`
+	PerLine     map[string]money.Money `json:"per_line"` // Line name -> share of the discount (for tax)
+}
+
+// OrderTax is the jurisdiction and mode the tax was calculated with
+type OrderTax struct {
+	Jurisdiction     string              `json:"jurisdiction"`
+	PricesIncludeTax bool                `json:"prices_include_tax,omitempty"`
+	Rates            map[string]tax.Rate `json:"rates"` // Tax class -> rate in millionths
+	DefaultClass     string              `json:"default_class,omitempty"`
+	Mode             string              `json:"mode"`
+}
+
+// Order returns what is on the bill
+func (bill *Bill) Order() Order {
+	order := Order{
+		Name:     bill.Name,
+		Currency: bill.Currency,
+		OpenedAt: bill.OpenedAt,
+		Tip:      bill.Tip,
+		Coupons:  bill.coupons,
+	}
+	for _, item := range bill.Items {
+		order.Lines = append(order.Lines, OrderLine{
+			Name:      item.Name,
+			Modifiers: item.Modifiers,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+			TaxClass:  item.TaxClass,
+			Combo:     item.Combo,
+		})
+	}
+
+	order.Charged = &Charged{Discounts: []OrderDiscount{}}
+	for _, discount := range bill.Discounts() {
+		order.Charged.Discounts = append(order.Charged.Discounts, OrderDiscount{
+			Name:        discount.Name,
+			Amount:      discount.Amount,
+			Explanation: discount.Explanation,
+			PerLine:     discount.PerItem,
+		})
+	}
+	if bill.taxRules != nil {
+		order.Charged.Tax = &OrderTax{
+			Jurisdiction:     bill.taxRules.Name,
+			PricesIncludeTax: bill.taxRules.PricesIncludeTax,
+			Rates:            bill.taxRules.Rates,
+			DefaultClass:     bill.taxRules.DefaultClass,
+			Mode:             bill.taxMode.String(),
+		}
+	}
+	return order
+}
+
+// SaveOrder writes the order as JSON
+func (bill *Bill) SaveOrder(path string) error {
+	data, err := json.MarshalIndent(bill.Order(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadOrder reads an order saved by SaveOrder
+func LoadOrder(path string) (Order, error) {
+	var order Order
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return order, err
+	}
+	if err := json.Unmarshal(data, &order); err != nil {
+		return order, fmt.Errorf("%s: %w", path, err)
+	}
+	return order, nil
+}
+
+// FromOrder rebuilds a bill with the prices stored on its lines
+// Orders saved before prices were stored are priced from the given menu instead:
+// pass history.AsOf(order.OpenedAt) to get the prices of the time
+// With order.Charged the bill also gets the discounts and tax of the time;
+// without it, set the tax rules and promotions on the result yourself
+func FromOrder(order Order, fromMenu menu.Menu) (Bill, error) {
+	rebuilt := NewBill(order.Name, order.Currency)
+	rebuilt.OpenedAt = order.OpenedAt
+	for _, line := range order.Lines {
+		if line.Price.Currency() != "" {
+			if err := rebuilt.addLine(line); err != nil {
+				return Bill{}, err
+			}
+			continue
+		}
+		selection := menu.Selection{Product: line.Name, Modifiers: line.Modifiers}
+		if err := rebuilt.AddSelection(fromMenu, selection, line.Quantity); err != nil {
+			return Bill{}, err
+		}
+	}
+	if order.Tip.Currency() != "" {
+		if err := rebuilt.UpdateTip(order.Tip); err != nil {
+			return Bill{}, err
+		}
+	}
+	rebuilt.coupons = order.Coupons
+	if order.Charged != nil {
+		if err := rebuilt.setCharged(*order.Charged); err != nil {
+			return Bill{}, err
+		}
+	}
+	return rebuilt, nil
+}
+
+// setCharged fixes the discounts and tax rules to the ones stored with an order
+func (bill *Bill) setCharged(charged Charged) error {
+	bill.fixedDiscounts = []promo.Applied{}
+	for _, discount := range charged.Discounts {
+		bill.fixedDiscounts = append(bill.fixedDiscounts, promo.Applied{
+			Name:        discount.Name,
+			Amount:      discount.Amount,
+			Explanation: discount.Explanation,
+			PerItem:     discount.PerLine,
+		})
+	}
+	if charged.Tax == nil {
+		return nil
+	}
+	mode, err := tax.ParseMode(charged.Tax.Mode)
+	if err != nil {
+		return err
+	}
+	return bill.SetTax(tax.Jurisdiction{
+		Name:             charged.Tax.Jurisdiction,
+		PricesIncludeTax: charged.Tax.PricesIncludeTax,
+		Rates:            charged.Tax.Rates,
+		DefaultClass:     charged.Tax.DefaultClass,
+	}, mode)
+}
+
+// addLine puts a saved line back on the bill at the price it was charged
+func (bill *Bill) addLine(line OrderLine) error {
+	if line.Quantity <= 0 {
+		return fmt.Errorf("%s: quantity must be at least 1", line.Name)
+	}
+	if line.Price.Currency() != bill.Currency {
+		return fmt.Errorf("%s is priced in %s, the bill is in %s", line.Name, line.Price.Currency(), bill.Currency)
+	}
+	if _, err := line.Price.Mul(int64(line.Quantity)); err != nil {
+		return fmt.Errorf("%s: %w", line.Name, err)
+	}
+	bill.Items = append(bill.Items, Item{
+		Name:      line.Name,
+		Modifiers: line.Modifiers,
+		Combo:     line.Combo,
+		Price:     line.Price,
+		Quantity:  line.Quantity,
+		TaxClass:  line.TaxClass,
+	})
+	return nil
+}
@@ -0,0 +1,146 @@
+package exchange
+
+import (
+	"17-SavingFiles/money"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// =====================
+// ROUNDING POLICY
+// =====================
+
+// Rounding decides what happens to a fraction of a cent after conversion
+type Rounding int
+
+const (
+	HalfEven Rounding = iota // Banker's rounding (default)
+	HalfUp                   // 0.5 cent rounds away from zero
+	Down                     // Always towards zero (customer-friendly for prices)
+	Up                       // Always away from zero
+)
+
+// ParseRounding reads a rounding name from a flag or config
+func ParseRounding(name string) (Rounding, error) {
+	switch name {
+	case "half-even", "":
+		return HalfEven, nil
+	case "half-up":
+		return HalfUp, nil
+	case "down":
+		return Down, nil
+	case "up":
+		return Up, nil
+	}
+	return 0, fmt.Errorf("unknown rounding %q (use half-even, half-up, down or up)", name)
+}
+
+// =====================
+// CONVERTER
+// =====================
+
+// ErrStaleRate is returned when the newest rate is older than MaxAge
+var ErrStaleRate = errors.New("exchange rate is too old")
+
+// Converter turns amounts into other currencies using a rates Table
+type Converter struct {
+	Table    *Table
+	Rounding Rounding
+	MaxAge   time.Duration    // 0 = rates never expire
+	Now      func() time.Time // Injectable clock; nil = time.Now
+}
+
+// NewConverter creates a converter with banker's rounding
+func NewConverter(table *Table, maxAge time.Duration) *Converter {
+	return &Converter{Table: table, Rounding: HalfEven, MaxAge: maxAge}
+}
+
+// Convert returns amount expressed in the target currency
+func (converter *Converter) Convert(amount money.Money, to string) (money.Money, error) {
+	from := amount.Currency()
+	if from == to {
+		return amount, nil
+	}
+
+	toDecimals, err := money.Decimals(to)
+	if err != nil {
+		return money.Money{}, err
+	}
+	fromDecimals, err := money.Decimals(from)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	now := time.Now()
+	if converter.Now != nil {
+		now = converter.Now()
+	}
+	rate, ok := converter.Table.Lookup(from, to, now)
+	if !ok {
+		return money.Money{}, fmt.Errorf("no exchange rate from %s to %s", from, to)
+	}
+	if age := now.Sub(rate.Date); converter.MaxAge > 0 && age > converter.MaxAge {
+		return money.Money{}, fmt.Errorf("%w: %s->%s is from %s", ErrStaleRate, from, to, rate.Date.Format(dateLayout))
+	}
+
+	// minorTo = minorFrom * rate * 10^toDecimals / 10^fromDecimals, exactly
+	value := new(big.Rat).SetInt64(amount.Minor())
+	value.Mul(value, rate.Value)
+	value.Mul(value, new(big.Rat).SetInt(pow10(toDecimals)))
+	value.Quo(value, new(big.Rat).SetInt(pow10(fromDecimals)))
+
+	minor := round(value, converter.Rounding)
+	if !minor.IsInt64() {
+		return money.Money{}, fmt.Errorf("%s is too large to convert", amount)
+	}
+	return money.New(minor.Int64(), to), nil
+}
+
+// Func returns a function converting any amount into one currency
+// Handy for formatting code that should not know about rate tables
+func (converter *Converter) Func(to string) func(money.Money) (money.Money, error) {
+	return func(amount money.Money) (money.Money, error) {
+		return converter.Convert(amount, to)
+	}
+}
+
+// =====================
+// PRIVATE HELPERS
+// =====================
+
+// pow10 returns 10^n as a big.Int
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// round turns an exact fraction into a whole number of minor units
+func round(value *big.Rat, rounding Rounding) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	// Compare 2*|remainder| with the denominator: below, at or above half
+	doubled := new(big.Int).Abs(remainder)
+	doubled.Lsh(doubled, 1)
+	half := doubled.Cmp(value.Denom())
+
+	awayFromZero := false
+	switch rounding {
+	case HalfEven:
+		awayFromZero = half > 0 || (half == 0 && quotient.Bit(0) == 1)
+	case HalfUp:
+		awayFromZero = half >= 0
+	case Up:
+		awayFromZero = true
+	case Down:
+		awayFromZero = false
+	}
+
+	if awayFromZero {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	return quotient
+}
@@ -0,0 +1,166 @@
+package exchange
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =====================
+// RATES TABLE
+// =====================
+
+// dateLayout is how rate dates are written in the files (YYYY-MM-DD)
+const dateLayout = "2006-01-02"
+
+// Rate says 1 unit of From is worth Value units of To on Date
+type Rate struct {
+	From  string
+	To    string
+	Value *big.Rat // Exact decimal (e.g., 0.92), never float64
+	Date  time.Time
+}
+
+// Table holds every known rate, oldest first per currency pair
+type Table struct {
+	rates map[string][]Rate // "USD/EUR" -> rates sorted by date
+}
+
+// NewTable creates an empty rates table
+func NewTable() *Table {
+	return &Table{rates: map[string][]Rate{}}
+}
+
+// pairKey builds the map key for a currency pair
+func pairKey(from string, to string) string {
+	return from + "/" + to
+}
+
+// Add stores a rate; rates for the same pair are kept sorted by date
+func (table *Table) Add(rate Rate) error {
+	if rate.Value == nil || rate.Value.Sign() <= 0 {
+		return fmt.Errorf("rate %s->%s must be above 0", rate.From, rate.To)
+	}
+	key := pairKey(rate.From, rate.To)
+	table.rates[key] = append(table.rates[key], rate)
+	sort.SliceStable(table.rates[key], func(i, j int) bool {
+		return table.rates[key][i].Date.Before(table.rates[key][j].Date)
+	})
+	return nil
+}
+
+// Lookup returns the newest rate from -> to dated on or before "at"
+// The opposite pair counts too, inverted (1 / rate): a fresh EUR->USD rate
+// beats a stale USD->EUR one; on the same date the direct rate wins
+func (table *Table) Lookup(from string, to string, at time.Time) (Rate, bool) {
+	direct, hasDirect := latest(table.rates[pairKey(from, to)], at)
+	opposite, hasOpposite := latest(table.rates[pairKey(to, from)], at)
+	if hasOpposite && (!hasDirect || opposite.Date.After(direct.Date)) {
+		inverse := new(big.Rat).Inv(opposite.Value)
+		return Rate{From: from, To: to, Value: inverse, Date: opposite.Date}, true
+	}
+	return direct, hasDirect
+}
+
+// latest finds the last rate not after "at" in a date-sorted list
+func latest(rates []Rate, at time.Time) (Rate, bool) {
+	for i := len(rates) - 1; i >= 0; i-- {
+		if !rates[i].Date.After(at) {
+			return rates[i], true
+		}
+	}
+	return Rate{}, false
+}
+
+// =====================
+// LOADING FROM FILES
+// =====================
+
+// jsonRate is one entry in a rates JSON file:
+// [{"from": "USD", "to": "EUR", "rate": "0.92", "date": "2026-10-18"}]
+type jsonRate struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Rate string `json:"rate"` // A string so the decimal stays exact
+	Date string `json:"date"`
+}
+
+// LoadFile reads rates from a .json or .csv file (chosen by extension)
+func LoadFile(path string) (*Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return LoadJSON(file)
+	case ".csv":
+		return LoadCSV(file)
+	}
+	return nil, fmt.Errorf("%s: rates file must be .json or .csv", path)
+}
+
+// LoadJSON reads a JSON list of rates
+func LoadJSON(reader io.Reader) (*Table, error) {
+	var entries []jsonRate
+	if err := json.NewDecoder(reader).Decode(&entries); err != nil {
+		return nil, err
+	}
+
+	table := NewTable()
+	for i, entry := range entries {
+		if err := table.addText(entry.From, entry.To, entry.Rate, entry.Date); err != nil {
+			return nil, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+	}
+	return table, nil
+}
+
+// LoadCSV reads rates with the header: from,to,rate,date
+func LoadCSV(reader io.Reader) (*Table, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	table := NewTable()
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "from") {
+			continue // Header row
+		}
+		if len(record) != 4 {
+			return nil, fmt.Errorf("line %d: expected from,to,rate,date", i+1)
+		}
+		if err := table.addText(record[0], record[1], record[2], record[3]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	return table, nil
+}
+
+// addText parses the text fields of a rate and adds it
+func (table *Table) addText(from string, to string, value string, date string) error {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return fmt.Errorf("%q is not a rate", value)
+	}
+	day, err := time.Parse(dateLayout, strings.TrimSpace(date))
+	if err != nil {
+		return fmt.Errorf("%q is not a YYYY-MM-DD date", date)
+	}
+	return table.Add(Rate{
+		From:  strings.ToUpper(strings.TrimSpace(from)),
+		To:    strings.ToUpper(strings.TrimSpace(to)),
+		Value: rate,
+		Date:  day,
+	})
+}
@@ -0,0 +1,3 @@
+module 17-SavingFiles
+
+go 1.25.5
@@ -0,0 +1,217 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// =====================
+// STRUCT DEFINITIONS
+// =====================
+
+// Stock is how much of one thing is on the shelf
+// A thing is an ingredient ("flour") or a ready-made menu item ("pie")
+type Stock struct {
+	Quantity     int64  `json:"quantity"`
+	Unit         string `json:"unit"`          // "g", "ml", "pcs"...
+	ReorderLevel int64  `json:"reorder_level"` // Reorder when Quantity falls to this level
+	ReorderQty   int64  `json:"reorder_qty"`   // How much to order then
+}
+
+// Recipe lists the ingredients used by one menu item: ingredient -> quantity
+type Recipe map[string]int64
+
+// Inventory holds all stock and recipes, and the file they are saved in
+type Inventory struct {
+	Stock   map[string]Stock  `json:"stock"`
+	Recipes map[string]Recipe `json:"recipes"`
+	path    string
+}
+
+// ErrOutOfStock is returned when an order needs more than is on the shelf
+var ErrOutOfStock = errors.New("out of stock")
+
+// =====================
+// LOADING AND SAVING
+// =====================
+
+// Load reads the inventory from a JSON file
+func Load(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := &Inventory{path: path}
+	if err := json.Unmarshal(data, inventory); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if inventory.Stock == nil {
+		inventory.Stock = map[string]Stock{}
+	}
+	if inventory.Recipes == nil {
+		inventory.Recipes = map[string]Recipe{}
+	}
+	return inventory, nil
+}
+
+// Save writes the inventory back to the file it was loaded from
+// It writes a temporary file first so a crash never leaves half a file behind
+func (inventory *Inventory) Save() error {
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := inventory.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, inventory.path)
+}
+
+// =====================
+// ORDERING AND RESTOCKING
+// =====================
+
+// needs returns what ordering quantity x item takes from the shelf
+// An item with a recipe uses its ingredients; otherwise it uses its own stock
+// An item with neither is not tracked and needs nothing
+func (inventory *Inventory) needs(item string, quantity int) map[string]int64 {
+	needed := map[string]int64{}
+	if recipe, ok := inventory.Recipes[item]; ok {
+		for ingredient, amount := range recipe {
+			needed[ingredient] += amount * int64(quantity)
+		}
+	} else if _, ok := inventory.Stock[item]; ok {
+		needed[item] = int64(quantity)
+	}
+	return needed
+}
+
+// Consume takes what quantity x item needs off the shelf
+// Nothing is taken unless everything is available (all or nothing)
+func (inventory *Inventory) Consume(item string, quantity int) error {
+	needed := inventory.needs(item, quantity)
+
+	short := []string{}
+	for _, name := range sortedKeys(needed) {
+		stock, ok := inventory.Stock[name]
+		if !ok || stock.Quantity < needed[name] {
+			short = append(short, fmt.Sprintf("%s (need %d%s, have %d%s)", name, needed[name], stock.Unit, stock.Quantity, stock.Unit))
+		}
+	}
+	if len(short) > 0 {
+		return fmt.Errorf("%w: %d x %s: %s", ErrOutOfStock, quantity, item, strings.Join(short, ", "))
+	}
+
+	for name, amount := range needed {
+		stock := inventory.Stock[name]
+		stock.Quantity -= amount
+		inventory.Stock[name] = stock
+	}
+	return nil
+}
+
+// Return puts back what quantity x item took (e.g., an item removed from a bill)
+func (inventory *Inventory) Return(item string, quantity int) {
+	for name, amount := range inventory.needs(item, quantity) {
+		stock := inventory.Stock[name]
+		stock.Quantity += amount
+		inventory.Stock[name] = stock
+	}
+}
+
+// Restock adds a delivery to the shelf
+func (inventory *Inventory) Restock(name string, quantity int64) error {
+	stock, ok := inventory.Stock[name]
+	if !ok {
+		return fmt.Errorf("%q is not tracked", name)
+	}
+	if quantity <= 0 {
+		return errors.New("restock quantity must be above zero")
+	}
+	stock.Quantity += quantity
+	inventory.Stock[name] = stock
+	return nil
+}
+
+// Available returns how many of a menu item can still be made
+// -1 means the item is not tracked (unlimited)
+func (inventory *Inventory) Available(item string) int64 {
+	needed := inventory.needs(item, 1)
+	if len(needed) == 0 {
+		return -1
+	}
+	available := int64(-1)
+	for name, amount := range needed {
+		if amount == 0 {
+			continue
+		}
+		canMake := inventory.Stock[name].Quantity / amount
+		if available < 0 || canMake < available {
+			available = canMake
+		}
+	}
+	return available
+}
+
+// =====================
+// LOW-STOCK REPORT
+// =====================
+
+// LowStockLine is one row of the low-stock report
+type LowStockLine struct {
+	Name string
+	Stock
+}
+
+// LowStock lists everything at or below its reorder level, alphabetically
+func (inventory *Inventory) LowStock() []LowStockLine {
+	low := []LowStockLine{}
+	for _, name := range sortedKeys(inventory.Stock) {
+		stock := inventory.Stock[name]
+		if stock.Quantity <= stock.ReorderLevel {
+			low = append(low, LowStockLine{Name: name, Stock: stock})
+		}
+	}
+	return low
+}
+
+// FormatLowStock returns the low-stock report as text
+func (inventory *Inventory) FormatLowStock() string {
+	low := inventory.LowStock()
+	if len(low) == 0 {
+		return "All stock is above its reorder level.\n"
+	}
+
+	formatted := fmt.Sprintf("%-15s %10s %10s %10s\n", "item", "in stock", "reorder at", "order")
+	for _, line := range low {
+		formatted += fmt.Sprintf("%-15s %10s %10s %10s\n", line.Name,
+			fmt.Sprint(line.Quantity, line.Unit),
+			fmt.Sprint(line.ReorderLevel, line.Unit),
+			fmt.Sprint(line.ReorderQty, line.Unit))
+	}
+	return formatted
+}
+
+// sortedKeys returns the keys of a map in alphabetical order
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// inventory.Load("inventory.json") -> read stock and recipes
+// inv.Consume("soup", 2)           -> take ingredients, or ErrOutOfStock
+// inv.FormatLowStock()             -> what to reorder
+// inv.Save()                       -> write back to disk
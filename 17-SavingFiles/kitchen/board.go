@@ -0,0 +1,81 @@
+package kitchen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =====================
+// STATUS BOARD
+// =====================
+
+// Board keeps the latest status of every order, for the terminal view
+type Board struct {
+	latest map[int]Update
+	placed map[int]time.Time
+}
+
+// NewBoard returns an empty board
+func NewBoard() *Board {
+	return &Board{latest: map[int]Update{}, placed: map[int]time.Time{}}
+}
+
+// Apply records an update from the kitchen
+func (board *Board) Apply(update Update) {
+	board.latest[update.Order.ID] = update
+	if update.Status == Queued {
+		board.placed[update.Order.ID] = update.At
+	}
+}
+
+// Count is how many orders have a status
+func (board *Board) Count(status Status) int {
+	count := 0
+	for _, update := range board.latest {
+		if update.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Format returns one line per order, oldest first
+// Waiting time is counted from placing the order until now (or until it was ready)
+func (board *Board) Format(now time.Time) string {
+	ids := make([]int, 0, len(board.latest))
+	for id := range board.latest {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	formatted := fmt.Sprintf("%-4s %-10s %-4s %-10s %-8s %-7s %s\n", "#", "table", "prio", "status", "cook", "waited", "items")
+	for _, id := range ids {
+		update := board.latest[id]
+		until := now
+		if update.Status == Ready || update.Status == Cancelled {
+			until = update.At
+		}
+		items := make([]string, len(update.Order.Items))
+		for i, item := range update.Order.Items {
+			items[i] = fmt.Sprintf("%d x %s", item.Quantity, item.Name)
+		}
+		formatted += fmt.Sprintf("%-4d %-10s %-4d %-10s %-8s %-7s %s\n", id, update.Order.Table, update.Order.Priority,
+			update.Status, update.Cook, until.Sub(board.placed[id]).Round(time.Second), strings.Join(items, ", "))
+	}
+	formatted += fmt.Sprintf("\nqueued %d, cooking %d, ready %d", board.Count(Queued), board.Count(Cooking), board.Count(Ready))
+	if cancelled := board.Count(Cancelled); cancelled > 0 {
+		formatted += fmt.Sprintf(", cancelled %d", cancelled)
+	}
+	return formatted + "\n"
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// k := kitchen.NewKitchen(3, nil, clock)  -> 3 cooks
+// go k.Run(ctx)                           -> cooks start; updates closes when done
+// k.Place(order)                          -> from any goroutine
+// for update := range k.Updates() {...}   -> queued, cooking, ready
+// kitchen.NewKitchen(3, nil, fakeClock)  -> Run moves the clock: same updates every run
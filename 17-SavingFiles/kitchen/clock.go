@@ -0,0 +1,178 @@
+package kitchen
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// =====================
+// CLOCKS
+// =====================
+
+// Clock is where the kitchen gets the time from and how cooks wait
+// The real clock is used in the restaurant; the fake clock makes a run
+// repeatable, because time only moves when Advance is called
+type Clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error // Returns ctx.Err() when cancelled
+}
+
+// RealClock runs Speed times faster than the wall clock (0 or 1 = real time)
+// With Speed 60 a 10 minute soup is ready after 10 seconds
+type RealClock struct {
+	Speed float64
+	start time.Time
+}
+
+// NewRealClock returns a clock that starts now and runs speed times faster
+func NewRealClock(speed float64) *RealClock {
+	if speed <= 0 {
+		speed = 1
+	}
+	return &RealClock{Speed: speed, start: time.Now()}
+}
+
+// Now returns the kitchen time
+func (clock *RealClock) Now() time.Time {
+	elapsed := time.Since(clock.start)
+	return clock.start.Add(time.Duration(float64(elapsed) * clock.Speed))
+}
+
+// Sleep waits d of kitchen time
+func (clock *RealClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(time.Duration(float64(d) / clock.Speed))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sleeper is one goroutine waiting on the fake clock
+type sleeper struct {
+	wake time.Time
+	done chan struct{}
+}
+
+// FakeClock only moves when told to, so every run sees the same times
+type FakeClock struct {
+	lock     sync.Mutex
+	now      time.Time
+	sleepers []*sleeper
+	slept    chan struct{} // Closed (and replaced) whenever someone starts waiting
+}
+
+// NewFakeClock returns a fake clock set to start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start, slept: make(chan struct{})}
+}
+
+// Now returns the fake time
+func (clock *FakeClock) Now() time.Time {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	return clock.now
+}
+
+// Sleep waits until the clock has been advanced by d
+func (clock *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	clock.lock.Lock()
+	waiting := &sleeper{wake: clock.now.Add(d), done: make(chan struct{})}
+	clock.sleepers = append(clock.sleepers, waiting)
+	if clock.slept != nil {
+		close(clock.slept)
+	}
+	clock.slept = make(chan struct{})
+	clock.lock.Unlock()
+
+	select {
+	case <-waiting.done:
+		return nil
+	case <-ctx.Done():
+		clock.lock.Lock()
+		clock.remove(waiting)
+		clock.lock.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Sleepers is how many goroutines are waiting on the clock
+func (clock *FakeClock) Sleepers() int {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	return len(clock.sleepers)
+}
+
+// waitSleepers waits until at least n goroutines are waiting on the clock
+func (clock *FakeClock) waitSleepers(ctx context.Context, n int) error {
+	for {
+		clock.lock.Lock()
+		if len(clock.sleepers) >= n {
+			clock.lock.Unlock()
+			return nil
+		}
+		if clock.slept == nil {
+			clock.slept = make(chan struct{})
+		}
+		slept := clock.slept
+		clock.lock.Unlock()
+
+		select {
+		case <-slept:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Advance moves the clock forward and wakes everyone whose wait is over (earliest first)
+func (clock *FakeClock) Advance(d time.Duration) {
+	clock.lock.Lock()
+	defer clock.lock.Unlock()
+	clock.now = clock.now.Add(d)
+
+	sort.SliceStable(clock.sleepers, func(i, j int) bool {
+		return clock.sleepers[i].wake.Before(clock.sleepers[j].wake)
+	})
+	for len(clock.sleepers) > 0 && !clock.sleepers[0].wake.After(clock.now) {
+		close(clock.sleepers[0].done)
+		clock.sleepers = clock.sleepers[1:]
+	}
+}
+
+// AdvanceToNext moves the clock to the first wake-up; false when nobody is waiting
+func (clock *FakeClock) AdvanceToNext() bool {
+	clock.lock.Lock()
+	if len(clock.sleepers) == 0 {
+		clock.lock.Unlock()
+		return false
+	}
+	next := clock.sleepers[0].wake
+	for _, waiting := range clock.sleepers[1:] {
+		if waiting.wake.Before(next) {
+			next = waiting.wake
+		}
+	}
+	d := next.Sub(clock.now)
+	clock.lock.Unlock()
+
+	clock.Advance(d)
+	return true
+}
+
+// remove forgets a sleeper (the caller holds the lock)
+func (clock *FakeClock) remove(waiting *sleeper) {
+	for i, other := range clock.sleepers {
+		if other == waiting {
+			clock.sleepers = append(clock.sleepers[:i], clock.sleepers[i+1:]...)
+			return
+		}
+	}
+}
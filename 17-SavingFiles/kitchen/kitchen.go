@@ -0,0 +1,344 @@
+package kitchen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// =====================
+// ORDERS AND STATUS UPDATES
+// =====================
+
+// Item is one product of an order
+type Item struct {
+	Name     string
+	Quantity int
+}
+
+// Order is one ticket for the kitchen
+type Order struct {
+	ID       int // Set by Place
+	Table    string
+	Items    []Item
+	Priority int       // Higher is cooked first (e.g., 1 for starters, 0 for the rest)
+	PlacedAt time.Time // Set by Place
+}
+
+// Status is where an order is in the kitchen
+type Status int
+
+const (
+	Queued Status = iota
+	Cooking
+	Ready
+	Cancelled // The kitchen closed before the order was ready
+)
+
+// String implements fmt.Stringer, so %s prints "cooking" instead of 1
+func (status Status) String() string {
+	switch status {
+	case Queued:
+		return "queued"
+	case Cooking:
+		return "cooking"
+	case Ready:
+		return "ready"
+	case Cancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("Status(%d)", int(status))
+}
+
+// Update is posted on the updates channel every time an order changes status
+type Update struct {
+	Order  Order
+	Status Status
+	Cook   string // Empty while queued
+	At     time.Time
+}
+
+// =====================
+// THE KITCHEN
+// =====================
+
+// PrepTimes maps a product to how long one takes to make
+type PrepTimes map[string]time.Duration
+
+// DefaultPrep is used for products without a prep time
+const DefaultPrep = 5 * time.Minute
+
+// DefaultPrepTimes are the prep times for the default menu
+func DefaultPrepTimes() PrepTimes {
+	return PrepTimes{
+		"soup":              8 * time.Minute,
+		"rice":              12 * time.Minute,
+		"pie":               3 * time.Minute,
+		"salad":             4 * time.Minute,
+		"coffee":            2 * time.Minute,
+		"wine":              1 * time.Minute,
+		"soup + rice combo": 15 * time.Minute,
+	}
+}
+
+// Kitchen is a queue of orders and a team of cooks working on it
+type Kitchen struct {
+	Cooks     int
+	PrepTimes PrepTimes
+
+	clock   Clock
+	queue   *Queue
+	updates chan Update
+
+	lock   sync.RWMutex // Place holds it for reading while it posts; Run closes updates under the write lock
+	done   bool         // Run has returned
+	nextID atomic.Int64
+}
+
+// NewKitchen returns a kitchen with that many cooks
+// A nil clock uses the real clock; nil prep times use DefaultPrepTimes
+func NewKitchen(cooks int, prepTimes PrepTimes, clock Clock) *Kitchen {
+	if clock == nil {
+		clock = NewRealClock(1)
+	}
+	if prepTimes == nil {
+		prepTimes = DefaultPrepTimes()
+	}
+	return &Kitchen{
+		Cooks:     max(cooks, 1),
+		PrepTimes: prepTimes,
+		clock:     clock,
+		queue:     NewQueue(),
+		updates:   make(chan Update, 64),
+	}
+}
+
+// Updates is the channel status updates are posted on
+// It must be read until it is closed (when Run returns), or the cooks stop working
+func (kitchen *Kitchen) Updates() <-chan Update {
+	return kitchen.updates
+}
+
+// PrepTime is how long one cook needs for an order: every item, one after the other
+func (kitchen *Kitchen) PrepTime(order Order) time.Duration {
+	total := time.Duration(0)
+	for _, item := range order.Items {
+		prep, ok := kitchen.PrepTimes[item.Name]
+		if !ok {
+			prep = DefaultPrep
+		}
+		total += prep * time.Duration(item.Quantity)
+	}
+	return total
+}
+
+// Place puts an order in the queue and returns its ID
+// It is safe to call from many goroutines; it fails with ErrClosed after Close
+func (kitchen *Kitchen) Place(order Order) (int, error) {
+	if len(order.Items) == 0 {
+		return 0, errors.New("kitchen: order has no items")
+	}
+	kitchen.lock.RLock()
+	defer kitchen.lock.RUnlock()
+	if kitchen.done || kitchen.queue.Closed() {
+		return 0, ErrClosed
+	}
+
+	order.ID = int(kitchen.nextID.Add(1))
+	order.PlacedAt = kitchen.clock.Now()
+
+	// Posted before the order is queued, so "queued" always comes before "cooking"
+	kitchen.updates <- Update{Order: order, Status: Queued, At: order.PlacedAt}
+	if err := kitchen.queue.Push(order); err != nil {
+		kitchen.updates <- Update{Order: order, Status: Cancelled, At: kitchen.clock.Now()}
+		return 0, err
+	}
+	return order.ID, nil
+}
+
+// Close stops taking orders; Run returns once the queued orders are ready
+func (kitchen *Kitchen) Close() {
+	kitchen.queue.Close()
+}
+
+// Run lets the cooks work until the kitchen is closed and empty, or ctx is cancelled
+// One dispatcher hands the most urgent order to the free cook with the lowest number
+// and posts every update; with a FakeClock it also moves the clock, so a run always
+// gives the same updates in the same order
+// Orders still queued or cooking when ctx is cancelled are posted as Cancelled
+// The updates channel is closed when Run returns
+func (kitchen *Kitchen) Run(ctx context.Context) {
+	fake, _ := kitchen.clock.(*FakeClock)
+	finished := make(chan finish, kitchen.Cooks)
+	cooks := make([]*cook, kitchen.Cooks)
+	var working sync.WaitGroup
+	for i := range cooks {
+		cooks[i] = &cook{name: fmt.Sprintf("cook %d", i+1), orders: make(chan Order, 1)}
+		working.Add(1)
+		go func() {
+			defer working.Done()
+			kitchen.work(ctx, i, cooks[i].orders, finished)
+		}()
+	}
+
+	busy := 0
+	for ctx.Err() == nil {
+		changed := kitchen.queue.changes() // Taken before popping, so no order is missed
+		busy += kitchen.dispatch(cooks)
+		if busy == 0 && kitchen.queue.drained() {
+			break // Closed and empty: everyone goes home
+		}
+
+		if fake != nil && busy > 0 && (busy == len(cooks) || kitchen.queue.Len() == 0) {
+			busy -= kitchen.step(ctx, fake, cooks, finished)
+			continue
+		}
+		select {
+		case done := <-finished:
+			kitchen.finish(cooks[done.cook], done.err)
+			busy--
+		case <-changed:
+		case <-ctx.Done():
+		}
+	}
+
+	// Cancelled: wait for the cooks still working, then report them in cook order
+	results := make([]error, len(cooks))
+	for ; busy > 0; busy-- {
+		done := <-finished
+		results[done.cook] = done.err
+	}
+	for i, cook := range cooks {
+		if cook.busy {
+			kitchen.finish(cook, results[i])
+		}
+		close(cook.orders)
+	}
+	working.Wait()
+
+	// Nobody takes orders from the queue any more: cancel them, most urgent first
+	kitchen.queue.Close()
+	for {
+		order, ok := kitchen.queue.tryPop()
+		if !ok {
+			break
+		}
+		kitchen.updates <- Update{Order: order, Status: Cancelled, At: kitchen.clock.Now()}
+	}
+
+	kitchen.lock.Lock()
+	close(kitchen.updates)
+	kitchen.done = true
+	kitchen.lock.Unlock()
+}
+
+// =====================
+// THE DISPATCHER
+// =====================
+
+// cook is what the dispatcher knows about one cook
+type cook struct {
+	name    string
+	orders  chan Order // The next order to make; closed when the kitchen closes
+	busy    bool
+	order   Order
+	readyAt time.Time
+}
+
+// finish is a cook reporting back: err is set when the order was cancelled
+type finish struct {
+	cook int
+	err  error
+}
+
+// work is one cook: make every order the dispatcher hands over, then report back
+func (kitchen *Kitchen) work(ctx context.Context, index int, orders <-chan Order, finished chan<- finish) {
+	for order := range orders {
+		err := kitchen.clock.Sleep(ctx, kitchen.PrepTime(order))
+		finished <- finish{cook: index, err: err}
+	}
+}
+
+// dispatch hands queued orders to free cooks, lowest cook number first
+// It returns how many orders were handed out
+func (kitchen *Kitchen) dispatch(cooks []*cook) int {
+	started := 0
+	for _, cook := range cooks {
+		if cook.busy {
+			continue
+		}
+		order, ok := kitchen.queue.tryPop()
+		if !ok {
+			break
+		}
+		now := kitchen.clock.Now()
+		cook.busy, cook.order, cook.readyAt = true, order, now.Add(kitchen.PrepTime(order))
+		kitchen.updates <- Update{Order: order, Status: Cooking, Cook: cook.name, At: now}
+		cook.orders <- order // Buffered: the cook is free, so it never blocks
+		started++
+	}
+	return started
+}
+
+// finish posts a cook's order as ready (or cancelled) and frees the cook
+func (kitchen *Kitchen) finish(cook *cook, err error) {
+	status := Ready
+	if err != nil {
+		status = Cancelled
+	}
+	kitchen.updates <- Update{Order: cook.order, Status: status, Cook: cook.name, At: kitchen.clock.Now()}
+	cook.busy = false
+}
+
+// step moves a fake clock to the next time a cook is ready, waits for the cooks that
+// are ready then and posts them in cook order; it returns how many cooks it freed
+// The clock is only moved once every other busy cook is waiting on it (a handshake,
+// not a guess), so no cook misses its wake-up
+func (kitchen *Kitchen) step(ctx context.Context, clock *FakeClock, cooks []*cook, finished <-chan finish) int {
+	due := dueCooks(cooks, clock.Now())
+	if len(due) == 0 {
+		sleeping := 0
+		for _, cook := range cooks {
+			if cook.busy {
+				sleeping++
+			}
+		}
+		if err := clock.waitSleepers(ctx, sleeping); err != nil {
+			return 0 // Cancelled: Run collects the cooks
+		}
+		clock.AdvanceToNext()
+		due = dueCooks(cooks, clock.Now())
+	}
+
+	// Cancelling can make other cooks report too: they are posted along with the rest
+	reported := map[int]error{}
+	for _, i := range due {
+		for {
+			if _, ok := reported[i]; ok {
+				break
+			}
+			done := <-finished
+			reported[done.cook] = done.err
+		}
+	}
+	for i, cook := range cooks {
+		if err, ok := reported[i]; ok {
+			kitchen.finish(cook, err)
+		}
+	}
+	return len(reported)
+}
+
+// dueCooks lists the busy cooks whose order is ready by now, in cook order
+func dueCooks(cooks []*cook, now time.Time) []int {
+	due := []int{}
+	for i, cook := range cooks {
+		if cook.busy && !cook.readyAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	return due
+}
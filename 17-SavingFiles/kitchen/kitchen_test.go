@@ -0,0 +1,163 @@
+package kitchen
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// start is when every test kitchen opens
+var start = time.Date(2026, 1, 1, 18, 0, 0, 0, time.UTC)
+
+// testPrepTimes keep the expected times easy to work out
+func testPrepTimes() PrepTimes {
+	return PrepTimes{
+		"soup":   8 * time.Minute,
+		"rice":   12 * time.Minute,
+		"wine":   1 * time.Minute,
+		"water":  0,
+		"coffee": 2 * time.Minute,
+	}
+}
+
+// runOrders places every order, closes the kitchen and runs it on a fake clock
+// It returns the updates as "15:04 #id status cook" lines
+func runOrders(t *testing.T, cooks int, orders []Order, cancelled bool) []string {
+	t.Helper()
+	k := NewKitchen(cooks, testPrepTimes(), NewFakeClock(start))
+	for _, order := range orders {
+		if _, err := k.Place(order); err != nil {
+			t.Fatalf("Place(%v): %v", order.Items, err)
+		}
+	}
+	k.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if cancelled {
+		cancel()
+	}
+	go k.Run(ctx)
+
+	lines := []string{}
+	for update := range k.Updates() {
+		line := fmt.Sprintf("%s #%d %s", update.At.Format("15:04"), update.Order.ID, update.Status)
+		if update.Cook != "" {
+			line += " " + update.Cook
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// order is a one-item order for a table
+func order(table string, name string, quantity int, priority int) Order {
+	return Order{Table: table, Items: []Item{{Name: name, Quantity: quantity}}, Priority: priority}
+}
+
+func TestRunUpdateSequence(t *testing.T) {
+	tests := []struct {
+		name      string
+		cooks     int
+		orders    []Order
+		cancelled bool
+		want      []string
+	}{
+		{
+			name:   "one cook works in priority order",
+			cooks:  1,
+			orders: []Order{order("t1", "rice", 1, 0), order("bar", "wine", 2, 2), order("t2", "soup", 1, 1)},
+			want: []string{
+				"18:00 #1 queued", "18:00 #2 queued", "18:00 #3 queued",
+				"18:00 #2 cooking cook 1",
+				"18:02 #2 ready cook 1",
+				"18:02 #3 cooking cook 1",
+				"18:10 #3 ready cook 1",
+				"18:10 #1 cooking cook 1",
+				"18:22 #1 ready cook 1",
+			},
+		},
+		{
+			name:   "free cooks are used lowest number first",
+			cooks:  3,
+			orders: []Order{order("t1", "soup", 1, 0), order("t2", "wine", 1, 0)},
+			want: []string{
+				"18:00 #1 queued", "18:00 #2 queued",
+				"18:00 #1 cooking cook 1",
+				"18:00 #2 cooking cook 2",
+				"18:01 #2 ready cook 2",
+				"18:08 #1 ready cook 1",
+			},
+		},
+		{
+			name:   "orders ready at the same time are posted in cook order",
+			cooks:  2,
+			orders: []Order{order("t1", "coffee", 1, 0), order("t2", "wine", 2, 0), order("t3", "soup", 1, 0)},
+			want: []string{
+				"18:00 #1 queued", "18:00 #2 queued", "18:00 #3 queued",
+				"18:00 #1 cooking cook 1",
+				"18:00 #2 cooking cook 2",
+				"18:02 #1 ready cook 1",
+				"18:02 #2 ready cook 2",
+				"18:02 #3 cooking cook 1",
+				"18:10 #3 ready cook 1",
+			},
+		},
+		{
+			name:   "no prep time is ready at once",
+			cooks:  1,
+			orders: []Order{order("t1", "water", 1, 0), order("t2", "wine", 1, 0)},
+			want: []string{
+				"18:00 #1 queued", "18:00 #2 queued",
+				"18:00 #1 cooking cook 1",
+				"18:00 #1 ready cook 1",
+				"18:00 #2 cooking cook 1",
+				"18:01 #2 ready cook 1",
+			},
+		},
+		{
+			name:      "cancelled before opening",
+			cooks:     2,
+			orders:    []Order{order("t1", "soup", 1, 0), order("t2", "rice", 1, 1)},
+			cancelled: true,
+			want: []string{
+				"18:00 #1 queued", "18:00 #2 queued",
+				"18:00 #2 cancelled", "18:00 #1 cancelled",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Many runs, so a result that depends on the scheduler shows up
+			for run := 0; run < 50; run++ {
+				got := runOrders(t, test.cooks, test.orders, test.cancelled)
+				if !slices.Equal(got, test.want) {
+					t.Fatalf("run %d:\ngot  %q\nwant %q", run, got, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceAfterClose(t *testing.T) {
+	k := NewKitchen(1, testPrepTimes(), NewFakeClock(start))
+	k.Close()
+	if _, err := k.Place(order("t1", "soup", 1, 0)); err != ErrClosed {
+		t.Fatalf("Place after Close: got %v, want ErrClosed", err)
+	}
+	go k.Run(context.Background())
+	for range k.Updates() {
+		t.Fatal("no updates expected")
+	}
+}
+
+func TestPrepTime(t *testing.T) {
+	k := NewKitchen(1, testPrepTimes(), nil)
+	got := k.PrepTime(Order{Items: []Item{{Name: "soup", Quantity: 2}, {Name: "cake", Quantity: 1}}})
+	if want := 16*time.Minute + DefaultPrep; got != want {
+		t.Fatalf("PrepTime: got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,145 @@
+package kitchen
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+)
+
+// =====================
+// PRIORITY QUEUE
+// =====================
+
+// ErrClosed is returned when the kitchen takes no more orders
+var ErrClosed = errors.New("kitchen: closed for orders")
+
+// orderHeap implements heap.Interface: highest priority first, then first placed
+type orderHeap []Order
+
+func (orders orderHeap) Len() int { return len(orders) }
+
+func (orders orderHeap) Less(i, j int) bool {
+	if orders[i].Priority != orders[j].Priority {
+		return orders[i].Priority > orders[j].Priority
+	}
+	return orders[i].ID < orders[j].ID
+}
+
+func (orders orderHeap) Swap(i, j int) { orders[i], orders[j] = orders[j], orders[i] }
+
+func (orders *orderHeap) Push(x any) { *orders = append(*orders, x.(Order)) }
+
+func (orders *orderHeap) Pop() any {
+	old := *orders
+	last := old[len(old)-1]
+	*orders = old[:len(old)-1]
+	return last
+}
+
+// Queue hands orders to cooks, most urgent first
+// Any number of goroutines can Push and Pop at the same time
+type Queue struct {
+	lock    sync.Mutex
+	orders  orderHeap
+	changed chan struct{} // Closed (and replaced) whenever an order arrives or the queue closes
+	closed  bool
+}
+
+// NewQueue returns an empty queue
+func NewQueue() *Queue {
+	return &Queue{changed: make(chan struct{})}
+}
+
+// Push adds an order; it fails with ErrClosed after Close
+func (queue *Queue) Push(order Order) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	if queue.closed {
+		return ErrClosed
+	}
+	heap.Push(&queue.orders, order)
+	queue.wake()
+	return nil
+}
+
+// Pop waits for the most urgent order
+// It fails with ErrClosed once the queue is closed and empty, or with ctx.Err()
+func (queue *Queue) Pop(ctx context.Context) (Order, error) {
+	queue.lock.Lock()
+	for {
+		if len(queue.orders) > 0 {
+			order := heap.Pop(&queue.orders).(Order)
+			queue.lock.Unlock()
+			return order, nil
+		}
+		if queue.closed {
+			queue.lock.Unlock()
+			return Order{}, ErrClosed
+		}
+
+		// Wait outside the lock until something changes
+		changed := queue.changed
+		queue.lock.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Order{}, ctx.Err()
+		}
+		queue.lock.Lock()
+	}
+}
+
+// tryPop takes the most urgent order without waiting; false when the queue is empty
+func (queue *Queue) tryPop() (Order, bool) {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	if len(queue.orders) == 0 {
+		return Order{}, false
+	}
+	return heap.Pop(&queue.orders).(Order), true
+}
+
+// Close stops new orders; cooks finish what is queued
+func (queue *Queue) Close() {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	if !queue.closed {
+		queue.closed = true
+		queue.wake()
+	}
+}
+
+// Closed reports whether Close was called
+func (queue *Queue) Closed() bool {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	return queue.closed
+}
+
+// Len is how many orders are waiting
+func (queue *Queue) Len() int {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	return len(queue.orders)
+}
+
+// changes returns a channel that is closed when an order arrives or the queue closes
+func (queue *Queue) changes() <-chan struct{} {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	return queue.changed
+}
+
+// drained reports whether the queue is closed and empty
+func (queue *Queue) drained() bool {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	return queue.closed && len(queue.orders) == 0
+}
+
+// wake tells every waiting cook to look again (the caller holds the lock)
+func (queue *Queue) wake() {
+	close(queue.changed)
+	queue.changed = make(chan struct{})
+}
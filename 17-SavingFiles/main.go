@@ -1,7 +1,579 @@
 package main
 
-import "fmt"
+import (
+	"17-SavingFiles/bill"
+	"17-SavingFiles/exchange"
+	"17-SavingFiles/inventory"
+	"17-SavingFiles/kitchen"
+	"17-SavingFiles/menu"
+	"17-SavingFiles/money"
+	"17-SavingFiles/promo"
+	"17-SavingFiles/tax"
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// billsDir is the folder the receipts are saved in
+const billsDir = "bills"
+
+// historyLock guards the price history: the menu file watcher records
+// changes from its own goroutine while the prompt can change prices too
+var historyLock sync.Mutex
+
+// stockLock guards the inventory: Ctrl+C puts the stock of an unsaved bill
+// back from its own goroutine while the prompt can be taking stock
+var stockLock sync.Mutex
 
 func main() {
-	fmt.Println("Hello, World!")
-}
\ No newline at end of file
+	// =====================
+	// RESTAURANT BILL APP
+	// =====================
+
+	ratesPath := flag.String("rates", "", "exchange rates file (.json or .csv)")
+	showCurrency := flag.String("show", "", "also show prices in this currency (needs --rates)")
+	maxAge := flag.Duration("max-age", 72*time.Hour, "refuse exchange rates older than this")
+	rounding := flag.String("rounding", "half-even", "rounding for converted amounts (half-even, half-up, down, up)")
+	taxRulesPath := flag.String("tax-rules", "", "tax rules file (JSON, see tax.example.json)")
+	jurisdiction := flag.String("jurisdiction", "", "jurisdiction from the tax rules to apply (e.g., NL)")
+	taxMode := flag.String("tax-mode", "line", "round tax per line or per bill (line, bill)")
+	promotionsPath := flag.String("promotions", "", "promotions file (JSON, see promotions.example.json)")
+	coupons := flag.String("coupon", "", "comma-separated coupon codes")
+	inventoryPath := flag.String("inventory", "", "inventory file to take stock from (JSON, see inventory.example.json)")
+	historyPath := flag.String("history", "menu_history.json", "menu price history file")
+	menuPath := flag.String("menu", "", "load the menu from a .csv or .json file (reloaded when it changes)")
+	cooks := flag.Int("cooks", 3, "number of cooks for the kitchen command")
+	speed := flag.Float64("speed", 60, "kitchen command: how much faster than real time the kitchen runs")
+	fakeClock := flag.Bool("fake-clock", false, "kitchen command: use a fake clock (same result every run, no waiting)")
+	flag.Parse()
+
+	convert, err := loadConverter(*ratesPath, *showCurrency, *maxAge, *rounding)
+	exitOnError(err)
+
+	// =====================
+	// COMMANDS
+	// =====================
+
+	// flag.Args() is whatever comes after the flags, e.g., "price-report 2026-01-01 2026-12-31"
+	args := flag.Args()
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	// The kitchen and check-menu never use the price history, so they leave its file alone
+	var history *menu.History
+	if command != "kitchen" && command != "check-menu" {
+		history, err = loadHistory(*historyPath)
+		exitOnError(err)
+	}
+
+	switch command {
+	case "":
+		// No command: the interactive bill (below)
+	case "menu-as-of":
+		at, err := parseDateArg(args, 1, true)
+		exitOnError(err)
+		fmt.Printf("Menu as of %s:\n%s", at.Format("2006-01-02 15:04"), history.AsOf(at).FormatWith(convert))
+		return
+	case "kitchen":
+		runKitchen(*cooks, *speed, *fakeClock)
+		return
+	case "check-menu":
+		if len(args) < 2 {
+			exitOnError(fmt.Errorf("usage: check-menu <menu.csv|menu.json>"))
+		}
+		checked, err := menu.LoadFile(args[1])
+		exitOnError(err)
+		fmt.Printf("%s is valid: %d products\n", args[1], len(checked))
+		return
+	case "price-report":
+		from, err := parseDateArg(args, 1, false)
+		exitOnError(err)
+		to, err := parseDateArg(args, 2, true)
+		exitOnError(err)
+		fmt.Print(history.FormatReport(from, to))
+		return
+	case "reprint":
+		if len(args) < 2 {
+			exitOnError(fmt.Errorf("usage: reprint %s/<bill>.json", billsDir))
+		}
+		order, err := bill.LoadOrder(args[1])
+		exitOnError(err)
+
+		// Lines keep the price they were charged, and the order keeps its discounts and tax rules
+		// Older orders fall back to the menu of that day and to the current rule files
+		oldBill, err := bill.FromOrder(order, history.AsOf(order.OpenedAt))
+		exitOnError(err)
+		if order.Charged == nil {
+			fmt.Println("(saved before discounts and tax were stored: using the current --tax-rules and --promotions)")
+			exitOnError(applyTax(&oldBill, *taxRulesPath, *jurisdiction, *taxMode))
+			exitOnError(applyPromotions(&oldBill, *promotionsPath, "", func() time.Time { return order.OpenedAt }))
+		}
+		fmt.Print(oldBill.FormatWith(convert))
+		return
+	default:
+		exitOnError(fmt.Errorf("unknown command %q (use menu-as-of, price-report, reprint, check-menu or kitchen)", command))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	live := menu.NewLive(history.AsOf(time.Now()))
+	if *menuPath != "" {
+		loaded, err := menu.LoadFile(*menuPath)
+		exitOnError(err)
+		exitOnError(recordMenu(history, loaded))
+		live.Set(loaded)
+
+		ctx, stop := context.WithCancel(context.Background())
+		defer stop()
+		go menu.Watch(ctx, *menuPath, time.Second, func(reloaded menu.Menu, err error) {
+			if err == nil {
+				err = recordMenu(history, reloaded)
+			}
+			if err != nil {
+				fmt.Println("\nMenu not reloaded, keeping the old one:\n" + err.Error())
+				return
+			}
+			live.Set(reloaded)
+			fmt.Println("\nMenu reloaded from " + *menuPath)
+		})
+	}
+
+	myBill := createBill(reader)
+	exitOnError(applyTax(&myBill, *taxRulesPath, *jurisdiction, *taxMode))
+	exitOnError(applyPromotions(&myBill, *promotionsPath, *coupons, time.Now))
+	stock, err := loadInventory(&myBill, *inventoryPath)
+	exitOnError(err)
+	stopRelease := releaseOnInterrupt(&myBill, stock)
+	saved := promptOptions(reader, live, &myBill, convert, stock, history)
+	stopRelease()
+	if !saved {
+		releaseStock(&myBill, stock)
+	}
+}
+
+// runKitchen simulates a dinner rush: waiters place orders from their own
+// goroutines while the cooks work through the queue (Ctrl+C cancels the rest)
+func runKitchen(cooks int, speed float64, fake bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var clock kitchen.Clock = kitchen.NewRealClock(speed)
+	var fakeClock *kitchen.FakeClock
+	if fake {
+		fakeClock = kitchen.NewFakeClock(time.Date(2026, 1, 1, 18, 0, 0, 0, time.UTC))
+		clock = fakeClock
+	}
+	k := kitchen.NewKitchen(cooks, nil, clock)
+
+	// Every waiter takes the orders of their own tables; starters have priority
+	waiters := [][]kitchen.Order{
+		{{Table: "table 1", Items: []kitchen.Item{{Name: "soup", Quantity: 2}}, Priority: 1}, {Table: "table 1", Items: []kitchen.Item{{Name: "rice", Quantity: 2}}}},
+		{{Table: "table 2", Items: []kitchen.Item{{Name: "salad", Quantity: 1}}, Priority: 1}, {Table: "table 2", Items: []kitchen.Item{{Name: "soup + rice combo", Quantity: 1}, {Name: "pie", Quantity: 1}}}},
+		{{Table: "table 3", Items: []kitchen.Item{{Name: "coffee", Quantity: 3}, {Name: "pie", Quantity: 3}}}},
+		{{Table: "bar", Items: []kitchen.Item{{Name: "wine", Quantity: 2}}, Priority: 2}},
+	}
+	var placing sync.WaitGroup
+	for _, orders := range waiters {
+		placing.Add(1)
+		go func() {
+			defer placing.Done()
+			for _, order := range orders {
+				if _, err := k.Place(order); err != nil {
+					fmt.Fprintln(os.Stderr, "error:", err)
+				}
+			}
+		}()
+		if fake {
+			placing.Wait() // One waiter after the other, so the order numbers are the same every run
+		}
+	}
+
+	go func() {
+		placing.Wait()
+		k.Close() // No more orders tonight
+	}()
+	if fake {
+		// The fake clock moves as soon as every cook is busy or the queue is empty,
+		// so the rush is in before the kitchen opens (orders placed later would
+		// arrive at whatever fake time the goroutine scheduler allows)
+		placing.Wait()
+	}
+	go k.Run(ctx) // Closes the updates channel when the kitchen is done
+
+	// The live view redraws on every update, and every second for the waiting times
+	board := kitchen.NewBoard()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	updates := k.Updates()
+	for updates != nil {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				updates = nil // Run has returned
+				continue
+			}
+			board.Apply(update)
+			if fake {
+				// No redrawing: a log that is the same on every run
+				fmt.Printf("%s  #%d %-8s %-10s %s\n", update.At.Format("15:04"), update.Order.ID, update.Status, update.Order.Table, update.Cook)
+				continue
+			}
+		case <-ticker.C:
+			if fake {
+				continue
+			}
+		}
+		fmt.Print("\033[H\033[2J") // Clear the terminal, cursor to the top
+		fmt.Printf("Kitchen at %s (%d cooks)\n\n", clock.Now().Format("15:04:05"), k.Cooks)
+		fmt.Print(board.Format(clock.Now()))
+	}
+	fmt.Print("\n" + board.Format(clock.Now()))
+}
+
+// recordMenu adds the prices of a loaded menu file to the history
+func recordMenu(history *menu.History, loaded menu.Menu) error {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+	if history.Sync(loaded, time.Now()) == 0 {
+		return nil
+	}
+	return history.Save()
+}
+
+// exitOnError stops the program when err is not nil
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// loadHistory loads the menu price history
+// The first run records the default menu as the starting prices; later runs record
+// what changed in the default menu since. The file is only written when something changed
+func loadHistory(path string) (*menu.History, error) {
+	history, err := menu.LoadHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	if !history.SyncDefault(menu.Default(), time.Now()) {
+		return history, nil
+	}
+	return history, history.Save()
+}
+
+// parseDateArg reads args[index] as "2006-01-02" or an RFC 3339 time
+// A plain date means the start of that day, or its very end when endOfDay is true
+func parseDateArg(args []string, index int, endOfDay bool) (time.Time, error) {
+	if index >= len(args) {
+		return time.Time{}, fmt.Errorf("missing date (use YYYY-MM-DD)")
+	}
+	if moment, err := time.Parse(time.RFC3339, args[index]); err == nil {
+		return moment, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", args[index], time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date", args[index])
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return day, nil
+}
+
+// loadInventory loads the stock file and connects it to the bill
+// It returns nil when stock is not tracked
+func loadInventory(myBill *bill.Bill, path string) (*inventory.Inventory, error) {
+	if path == "" {
+		return nil, nil
+	}
+	stock, err := inventory.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	myBill.SetInventory(stock)
+	return stock, nil
+}
+
+// loadConverter builds the second-currency converter from the flags
+// It returns nil when no second currency was asked for
+func loadConverter(ratesPath string, showCurrency string, maxAge time.Duration, roundingName string) (money.Converter, error) {
+	if showCurrency == "" {
+		return nil, nil
+	}
+	if ratesPath == "" {
+		return nil, fmt.Errorf("--show %s needs a --rates file", showCurrency)
+	}
+
+	table, err := exchange.LoadFile(ratesPath)
+	if err != nil {
+		return nil, err
+	}
+	converter := exchange.NewConverter(table, maxAge)
+	if converter.Rounding, err = exchange.ParseRounding(roundingName); err != nil {
+		return nil, err
+	}
+	return converter.Func(strings.ToUpper(showCurrency)), nil
+}
+
+// applyTax loads the tax rules and sets them on the bill (nothing to do without --tax-rules)
+func applyTax(myBill *bill.Bill, rulesPath string, jurisdictionName string, modeName string) error {
+	if rulesPath == "" {
+		return nil
+	}
+
+	rules, err := tax.LoadRules(rulesPath)
+	if err != nil {
+		return err
+	}
+	jurisdiction, err := rules.Get(jurisdictionName)
+	if err != nil {
+		return err
+	}
+	mode, err := tax.ParseMode(modeName)
+	if err != nil {
+		return err
+	}
+	return myBill.SetTax(jurisdiction, mode)
+}
+
+// applyPromotions loads the promotion rules and coupons (nothing to do without --promotions)
+// clock says when the order is placed (happy hours)
+func applyPromotions(myBill *bill.Bill, promotionsPath string, coupons string, clock func() time.Time) error {
+	if promotionsPath == "" {
+		return nil
+	}
+
+	engine, err := promo.LoadFile(promotionsPath, myBill.Currency)
+	if err != nil {
+		return err
+	}
+	myBill.SetPromotions(engine, clock)
+
+	for _, code := range strings.Split(coupons, ",") {
+		if strings.TrimSpace(code) != "" {
+			myBill.AddCoupon(code)
+		}
+	}
+	return nil
+}
+
+// =====================
+// READING INPUT
+// =====================
+
+// getInput prints a prompt and returns the trimmed answer
+func getInput(prompt string, reader *bufio.Reader) (string, error) {
+	fmt.Print(prompt)
+	input, err := reader.ReadString('\n')
+	return strings.TrimSpace(input), err
+}
+
+// createBill asks for the bill name and returns an empty bill
+func createBill(reader *bufio.Reader) bill.Bill {
+	name, _ := getInput("Create a new bill name: ", reader)
+	return bill.NewBill(name, menu.Currency)
+}
+
+// =====================
+// OPTIONS LOOP
+// =====================
+
+// promptOptions keeps asking what to do until the bill is saved or the user quits
+// Pointer (*bill.Bill) so every change is made to the one real bill
+// convert (may be nil) shows amounts in a second currency
+// stock (may be nil) is saved after every change to the order
+// history records every price change made with option "m"
+// live holds the menu, which a file watcher may replace at any time
+// It reports whether the bill was saved
+func promptOptions(reader *bufio.Reader, live *menu.Live, myBill *bill.Bill, convert money.Converter, stock *inventory.Inventory, history *menu.History) bool {
+	for {
+		option, err := getInput("\nChoose option (a - add item, r - remove item, t - update tip, c - coupon, p - split, v - view, i - low stock, m - menu price, s - save bill, q - quit): ", reader)
+		if err != nil {
+			return false // End of input (e.g., Ctrl+D)
+		}
+
+		switch option {
+		case "a":
+			fmt.Print("\n" + live.Get().FormatWith(convert))
+			// Options follow the name: "rice, large, no onions"
+			name, _ := getInput("Item (name, options): ", reader)
+			selection := menu.ParseSelection(name)
+			quantity, err := readInt("Quantity: ", reader)
+			if err == nil {
+				err = addSelection(myBill, live.Get(), selection, quantity, stock)
+			}
+			report(err, "Item added - "+selection.Label())
+		case "r":
+			name, _ := getInput("Item to remove (as on the bill): ", reader)
+			quantity, err := readInt("Quantity to remove (0 = all): ", reader)
+			if err == nil {
+				err = removeItem(myBill, name, quantity, stock)
+			}
+			report(err, "Item removed - "+name)
+		case "t":
+			tipStr, _ := getInput("Enter tip amount ("+myBill.Currency+"): ", reader)
+			tip, err := money.Parse(tipStr, myBill.Currency)
+			if err == nil {
+				err = myBill.UpdateTip(tip)
+			}
+			report(err, "Tip updated - "+tipStr)
+		case "c":
+			code, _ := getInput("Coupon code: ", reader)
+			report(myBill.AddCoupon(code), "Coupon added - "+code)
+		case "p":
+			diners, err := readInt("Number of diners: ", reader)
+			if err != nil {
+				report(err, "")
+				continue
+			}
+			shares, err := myBill.Split(diners)
+			if err != nil {
+				report(err, "")
+				continue
+			}
+			for i, share := range shares {
+				fmt.Printf("Diner %d pays %s\n", i+1, share)
+			}
+		case "v":
+			fmt.Print("\n" + myBill.FormatWith(convert))
+		case "i":
+			if stock == nil {
+				fmt.Println("Stock is not tracked (start with --inventory)")
+				continue
+			}
+			stockLock.Lock()
+			fmt.Print("\n" + stock.FormatLowStock())
+			stockLock.Unlock()
+		case "m":
+			name, _ := getInput("Product name: ", reader)
+			priceStr, _ := getInput("New price ("+menu.Currency+"): ", reader)
+			price, err := money.Parse(priceStr, menu.Currency)
+			if err == nil {
+				err = updatePrice(live, history, name, price)
+			}
+			report(err, "Price updated - "+name)
+		case "s":
+			stockLock.Lock()
+			path, err := myBill.Save(billsDir, convert)
+			if err == nil {
+				myBill.SetInventory(nil) // Saved: what it took stays off the shelf
+			}
+			stockLock.Unlock()
+			report(err, "Bill saved to "+path)
+			if err == nil {
+				return true
+			}
+		case "q":
+			return false
+		default:
+			fmt.Println("That was not a valid option...")
+		}
+	}
+}
+
+// readInt asks for a whole number
+func readInt(prompt string, reader *bufio.Reader) (int, error) {
+	input, _ := getInput(prompt, reader)
+	number, err := strconv.Atoi(input)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a whole number", input)
+	}
+	return number, nil
+}
+
+// updatePrice changes one price in the live menu and records it in the history
+func updatePrice(live *menu.Live, history *menu.History, name string, price money.Money) error {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	changed := live.Get().Clone() // Never edit the menu other goroutines may be reading
+	if err := history.Update(changed, name, price, time.Now()); err != nil {
+		return err
+	}
+	live.Set(changed)
+	return history.Save()
+}
+
+// addSelection puts an item on the bill and saves the stock it took
+func addSelection(myBill *bill.Bill, current menu.Menu, selection menu.Selection, quantity int, stock *inventory.Inventory) error {
+	stockLock.Lock()
+	defer stockLock.Unlock()
+	if err := myBill.AddSelection(current, selection, quantity); err != nil {
+		return err
+	}
+	return saveStock(stock)
+}
+
+// removeItem takes an item off the bill and saves the stock it put back
+func removeItem(myBill *bill.Bill, name string, quantity int, stock *inventory.Inventory) error {
+	stockLock.Lock()
+	defer stockLock.Unlock()
+	if err := myBill.RemoveItem(name, quantity); err != nil {
+		return err
+	}
+	return saveStock(stock)
+}
+
+// releaseStock puts the stock of a bill that was not saved back on the shelf
+func releaseStock(myBill *bill.Bill, stock *inventory.Inventory) {
+	if stock == nil {
+		return
+	}
+	stockLock.Lock()
+	defer stockLock.Unlock()
+	myBill.ReleaseStock()
+	if err := saveStock(stock); err != nil {
+		fmt.Println("Error: stock of the unsaved bill not put back:", err)
+	}
+}
+
+// releaseOnInterrupt puts the stock of the bill back when Ctrl+C ends the app
+// The returned function stops listening (call it once the prompt is done)
+func releaseOnInterrupt(myBill *bill.Bill, stock *inventory.Inventory) func() {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		if _, ok := <-interrupts; ok {
+			fmt.Println("\nBill not saved")
+			releaseStock(myBill, stock)
+			os.Exit(130)
+		}
+	}()
+	return func() {
+		signal.Stop(interrupts)
+		close(interrupts)
+	}
+}
+
+// saveStock writes the inventory to disk, if stock is tracked
+func saveStock(stock *inventory.Inventory) error {
+	if stock == nil {
+		return nil
+	}
+	return stock.Save()
+}
+
+// report prints the error, or the success message when there is none
+func report(err error, success string) {
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(success)
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// os.WriteFile(path, []byte(text), 0644) -> save text to a file
+// os.MkdirAll(dir, 0755)                 -> create a folder (and parents)
+// 0644 = owner can read/write, others can only read
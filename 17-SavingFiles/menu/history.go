@@ -0,0 +1,290 @@
+package menu
+
+import (
+	"17-SavingFiles/money"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =====================
+// PRICE HISTORY
+// =====================
+
+// PriceChange records one product's price (and tax class) from a moment on
+// The options are recorded too, since their price differences are prices as well
+// A removed product is recorded as a change with Removed set (and a zero price)
+type PriceChange struct {
+	Product   string          `json:"product"`
+	Price     money.Money     `json:"price"`
+	TaxClass  string          `json:"tax_class"`
+	Category  string          `json:"category,omitempty"`
+	SoldOut   bool            `json:"sold_out,omitempty"`
+	Modifiers []ModifierGroup `json:"modifiers,omitempty"`
+	Combo     []string        `json:"combo,omitempty"`
+	Removed   bool            `json:"removed,omitempty"`
+	At        time.Time       `json:"at"`
+}
+
+// changeOf records a product as it is at a moment
+func changeOf(name string, product Product, at time.Time) PriceChange {
+	return PriceChange{
+		Product:   name,
+		Price:     product.Price,
+		TaxClass:  product.TaxClass,
+		Category:  product.Category,
+		SoldOut:   product.SoldOut,
+		Modifiers: product.Modifiers,
+		Combo:     product.Combo,
+		At:        at,
+	}
+}
+
+// removalOf records that a product left the menu at a moment
+// The price is zero in the product's last currency, so the file can still be read back
+func removalOf(name string, last Product, at time.Time) PriceChange {
+	return PriceChange{Product: name, Price: money.Zero(last.Price.Currency()), Removed: true, At: at}
+}
+
+// product is the menu product as the change recorded it
+func (change PriceChange) product() Product {
+	return Product{
+		Price:     change.Price,
+		TaxClass:  change.TaxClass,
+		Category:  change.Category,
+		SoldOut:   change.SoldOut,
+		Modifiers: change.Modifiers,
+		Combo:     change.Combo,
+	}
+}
+
+// History keeps every price change ever made, oldest first
+// UpdateMenu overwrites a price; History.Update also remembers the old one
+type History struct {
+	Changes  []PriceChange `json:"changes"`
+	Defaults []PriceChange `json:"defaults,omitempty"` // The built-in menu when it was last recorded
+	path     string
+}
+
+// LoadHistory reads the history file; a missing file gives an empty history
+func LoadHistory(path string) (*History, error) {
+	history := &History{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, history); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	history.sort()
+	return history, nil
+}
+
+// Save writes the history to its file
+func (history *History) Save() error {
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := history.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, history.path)
+}
+
+// IsEmpty reports whether no price was ever recorded
+func (history *History) IsEmpty() bool {
+	return len(history.Changes) == 0
+}
+
+// SyncDefault records what changed in the built-in menu since it was last recorded:
+// products added, removed or edited in Default. Prices changed later on (e.g., with
+// Update) are left alone unless Default changed that product too
+// The first time, with an empty history, it records the whole menu (the starting prices)
+// It reports whether the history changed and needs saving
+func (history *History) SyncDefault(defaults Menu, at time.Time) bool {
+	known := history.AsOf(at)
+	changed := 0
+	if len(history.Defaults) == 0 && !history.IsEmpty() {
+		// A history from before the built-in menu was kept: only add what it never saw
+		seen := map[string]bool{}
+		for _, change := range history.Changes {
+			seen[change.Product] = true
+		}
+		for _, name := range defaults.Names() {
+			if !seen[name] {
+				history.Changes = append(history.Changes, changeOf(name, defaults[name], at))
+				changed++
+			}
+		}
+	} else {
+		last := Menu{}
+		for _, change := range history.Defaults {
+			last[change.Product] = change.product()
+		}
+		for _, name := range defaults.Names() {
+			if old, ok := last[name]; ok && sameProduct(old, defaults[name]) {
+				continue
+			}
+			history.Changes = append(history.Changes, changeOf(name, defaults[name], at))
+			changed++
+		}
+		for _, name := range last.Names() {
+			if _, ok := defaults[name]; ok {
+				continue
+			}
+			if old, ok := known[name]; ok {
+				history.Changes = append(history.Changes, removalOf(name, old, at))
+				changed++
+			}
+		}
+		if changed == 0 && len(last) == len(defaults) {
+			return false
+		}
+	}
+
+	history.Defaults = nil
+	for _, name := range defaults.Names() {
+		history.Defaults = append(history.Defaults, changeOf(name, defaults[name], at))
+	}
+	history.sort()
+	return true
+}
+
+// Sync records every product that differs from the history at that moment, and every
+// product that is gone (used when a whole menu is loaded from a file)
+// It returns how many changes were recorded
+func (history *History) Sync(loaded Menu, at time.Time) int {
+	known := history.AsOf(at)
+	changed := 0
+	for _, name := range loaded.Names() {
+		product := loaded[name]
+		if old, ok := known[name]; ok && sameProduct(old, product) {
+			continue
+		}
+		history.Changes = append(history.Changes, changeOf(name, product, at))
+		changed++
+	}
+	for _, name := range known.Names() {
+		if _, ok := loaded[name]; !ok {
+			history.Changes = append(history.Changes, removalOf(name, known[name], at))
+			changed++
+		}
+	}
+	history.sort()
+	return changed
+}
+
+// Update changes the price in the menu like UpdateMenu, and records the change
+func (history *History) Update(menuToChange Menu, productName string, price money.Money, at time.Time) error {
+	if err := UpdateMenu(menuToChange, productName, price); err != nil {
+		return err
+	}
+	history.Changes = append(history.Changes, changeOf(productName, menuToChange[productName], at))
+	history.sort()
+	return nil
+}
+
+// AsOf rebuilds the menu as it was at a moment (later changes are ignored)
+func (history *History) AsOf(at time.Time) Menu {
+	menu := Menu{}
+	for _, change := range history.Changes {
+		if change.At.After(at) {
+			break // Changes are sorted, so everything after is later too
+		}
+		if change.Removed {
+			delete(menu, change.Product)
+			continue
+		}
+		menu[change.Product] = change.product()
+	}
+	return menu
+}
+
+// sameProduct reports whether two versions of a product have the same price, tax class,
+// category, availability, options and combo parts
+func sameProduct(old Product, product Product) bool {
+	return old.Price.Currency() == product.Price.Currency() && old.Price.Cmp(product.Price) == 0 &&
+		old.TaxClass == product.TaxClass && old.Category == product.Category && old.SoldOut == product.SoldOut &&
+		reflect.DeepEqual(old.Modifiers, product.Modifiers) && slices.Equal(old.Combo, product.Combo)
+}
+
+// sort keeps changes in time order (stable, so same-moment changes keep their order)
+func (history *History) sort() {
+	sort.SliceStable(history.Changes, func(i, j int) bool {
+		return history.Changes[i].At.Before(history.Changes[j].At)
+	})
+}
+
+// =====================
+// PRICE-CHANGE REPORT
+// =====================
+
+// ReportLine is one price change between two dates
+type ReportLine struct {
+	Product  string
+	OldPrice money.Money // Zero value (no currency) for a new product
+	NewPrice money.Money
+	At       time.Time
+}
+
+// ChangesBetween lists the price changes made from "from" up to and including "to"
+// Changes that set the same price again are left out
+func (history *History) ChangesBetween(from time.Time, to time.Time) []ReportLine {
+	lines := []ReportLine{}
+	last := map[string]money.Money{}
+	for _, change := range history.Changes {
+		if change.At.After(to) {
+			break
+		}
+		if change.Removed {
+			delete(last, change.Product) // Back on the menu later counts as new
+			continue
+		}
+		previous, known := last[change.Product]
+		last[change.Product] = change.Price
+
+		if change.At.Before(from) || (known && previous.Cmp(change.Price) == 0) {
+			continue
+		}
+		lines = append(lines, ReportLine{Product: change.Product, OldPrice: previous, NewPrice: change.Price, At: change.At})
+	}
+	return lines
+}
+
+// FormatReport returns the price changes between two dates as text
+func (history *History) FormatReport(from time.Time, to time.Time) string {
+	lines := history.ChangesBetween(from, to)
+	formatted := fmt.Sprintf("Price changes %s to %s:\n", from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if len(lines) == 0 {
+		return formatted + "  (none)\n"
+	}
+
+	for _, line := range lines {
+		old := "new"
+		change := ""
+		if line.OldPrice.Currency() != "" {
+			old = line.OldPrice.String()
+			difference := line.NewPrice.Sub(line.OldPrice)
+			sign := "+"
+			if difference.IsNegative() {
+				sign = ""
+			}
+			change = fmt.Sprintf(" (%s%s)", sign, difference)
+		}
+		row := fmt.Sprintf("  %s  %-12s %10s -> %-10s%s", line.At.Format("2006-01-02 15:04"), line.Product, old, line.NewPrice, change)
+		formatted += strings.TrimRight(row, " ") + "\n"
+	}
+	return formatted
+}
@@ -0,0 +1,315 @@
+package menu
+
+import (
+	"17-SavingFiles/money"
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// =====================
+// VALIDATION ERRORS
+// =====================
+
+// LineError is one problem found in a menu file
+type LineError struct {
+	Line    int // Line number in the file (1 = first line)
+	Message string
+}
+
+// Error implements the error interface
+func (lineError LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", lineError.Line, lineError.Message)
+}
+
+// ValidationError collects every problem in a file, not only the first one
+type ValidationError struct {
+	Path   string
+	Errors []LineError
+}
+
+// Error lists every problem, one per line
+func (validation *ValidationError) Error() string {
+	messages := make([]string, len(validation.Errors))
+	for i, lineError := range validation.Errors {
+		messages[i] = validation.Path + ": " + lineError.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// =====================
+// RAW ROWS
+// =====================
+
+// row is one product as written in the file, before validation
+type row struct {
+	line      int
+	name      string
+	price     string
+	category  string
+	taxClass  string
+	available string
+	combo     []string    // Parts, when the product is a combo
+	groups    []jsonGroup // Modifier groups (JSON menus only)
+}
+
+// =====================
+// LOADING
+// =====================
+
+// LoadFile reads a menu from .csv or .json and validates it strictly
+// All problems are returned together as a *ValidationError
+func LoadFile(path string) (Menu, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []row
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = csvRows(data)
+	case ".json":
+		rows, err = jsonRows(data)
+	default:
+		return nil, fmt.Errorf("%s: menu file must be .csv or .json", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return validate(path, rows)
+}
+
+// csvRows reads a CSV menu with a header row
+// Columns (any order): name, price, category, tax_class, available, combo
+// A combo lists its parts separated by "+", e.g., "soup + rice"
+func csvRows(data []byte) ([]row, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1 // Short rows give empty fields, which validate reports
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "price", "category", "tax_class"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("line 1: missing column %q", required)
+		}
+	}
+
+	rows := []row{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(column string) string {
+			index, ok := columns[column]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+		rows = append(rows, row{
+			line:      line,
+			name:      field("name"),
+			price:     field("price"),
+			category:  field("category"),
+			taxClass:  field("tax_class"),
+			available: field("available"),
+			combo:     splitCombo(field("combo")),
+		})
+	}
+	return rows, nil
+}
+
+// splitCombo reads "soup + rice" as its parts (empty = not a combo)
+func splitCombo(text string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(text, "+") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return parts
+}
+
+// jsonProduct is one product in a JSON menu:
+// [{"name": "soup", "price": "4.18", "category": "starter", "tax_class": "food", "available": true}]
+// Optional: "combo": ["soup", "rice"] and "modifiers": [{"name": "size", "required": true, "max": 1,
+// "default": "regular", "options": [{"name": "regular", "delta": "0"}, {"name": "large", "delta": "0.50"}]}]
+type jsonProduct struct {
+	Name      string      `json:"name"`
+	Price     any         `json:"price"` // "4.18" or 4.18
+	Category  string      `json:"category"`
+	TaxClass  string      `json:"tax_class"`
+	Available *bool       `json:"available"` // Missing = available
+	Combo     []string    `json:"combo"`
+	Modifiers []jsonGroup `json:"modifiers"`
+}
+
+// jsonGroup is a modifier group as written in a JSON menu
+type jsonGroup struct {
+	Name     string       `json:"name"`
+	Required bool         `json:"required"`
+	Max      int          `json:"max"`
+	Default  string       `json:"default"`
+	Options  []jsonOption `json:"options"`
+}
+
+// jsonOption is one modifier; delta is written like a price ("0.50" or 0.50, missing = free)
+type jsonOption struct {
+	Name  string `json:"name"`
+	Delta any    `json:"delta"`
+}
+
+// jsonRows reads a JSON list of products, remembering the line each starts on
+func jsonRows(data []byte) ([]row, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber() // Keep 4.18 as the text "4.18", never a float64
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errors.New("line 1: a JSON menu must be a list of products")
+	}
+
+	rows := []row{}
+	for decoder.More() {
+		// InputOffset is where the previous value ended; skip spaces to find this one
+		offset := int(decoder.InputOffset())
+		for offset < len(data) && strings.ContainsRune(" \t\r\n,", rune(data[offset])) {
+			offset++
+		}
+		line := 1 + bytes.Count(data[:offset], []byte("\n"))
+
+		var product jsonProduct
+		if err := decoder.Decode(&product); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		available := "yes"
+		if product.Available != nil && !*product.Available {
+			available = "no"
+		}
+		price := ""
+		if product.Price != nil {
+			price = fmt.Sprint(product.Price)
+		}
+		rows = append(rows, row{
+			line:      line,
+			name:      strings.TrimSpace(product.Name),
+			price:     price,
+			category:  strings.TrimSpace(product.Category),
+			taxClass:  strings.TrimSpace(product.TaxClass),
+			available: available,
+			combo:     product.Combo,
+			groups:    product.Modifiers,
+		})
+	}
+	return rows, nil
+}
+
+// =====================
+// VALIDATION
+// =====================
+
+// validate checks every row and builds the menu
+// It keeps going after a problem so the whole file is reported at once
+func validate(path string, rows []row) (Menu, error) {
+	menu := Menu{}
+	firstLine := map[string]int{}
+	problems := []LineError{}
+	report := func(line int, format string, args ...any) {
+		problems = append(problems, LineError{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, product := range rows {
+		key := strings.ToLower(product.name)
+		switch {
+		case product.name == "":
+			report(product.line, "missing name")
+			continue
+		case firstLine[key] != 0:
+			report(product.line, "duplicate name %q (first on line %d)", product.name, firstLine[key])
+			continue
+		}
+		firstLine[key] = product.line
+
+		price, err := money.Parse(product.price, Currency)
+		if err != nil {
+			report(product.line, "%s: price %q is not a valid %s amount", product.name, product.price, Currency)
+		} else if price.IsNegative() {
+			report(product.line, "%s: price %s is negative", product.name, price)
+		}
+
+		if !slices.Contains(Categories, product.category) {
+			report(product.line, "%s: unknown category %q (use %s)", product.name, product.category, strings.Join(Categories, ", "))
+		}
+		if product.taxClass == "" {
+			report(product.line, "%s: missing tax class", product.name)
+		}
+
+		soldOut := false
+		switch strings.ToLower(product.available) {
+		case "", "yes", "true", "1", "y":
+		case "no", "false", "0", "n":
+			soldOut = true
+		default:
+			report(product.line, "%s: available must be yes or no, not %q", product.name, product.available)
+		}
+
+		groups := make([]ModifierGroup, len(product.groups))
+		for i, group := range product.groups {
+			groups[i] = ModifierGroup{Name: strings.TrimSpace(group.Name), Required: group.Required, Max: group.Max, Default: strings.TrimSpace(group.Default)}
+			for _, option := range group.Options {
+				delta := money.Zero(Currency)
+				if option.Delta != nil {
+					delta, err = money.Parse(fmt.Sprint(option.Delta), Currency)
+					if err != nil {
+						report(product.line, "%s: option %q: delta %v is not a valid %s amount", product.name, option.Name, option.Delta, Currency)
+					}
+				}
+				groups[i].Options = append(groups[i].Options, Modifier{Name: strings.TrimSpace(option.Name), Delta: delta})
+			}
+		}
+		if len(groups) == 0 {
+			groups = nil
+		}
+
+		menu[product.name] = Product{Price: price, TaxClass: product.taxClass, Category: product.category, SoldOut: soldOut, Modifiers: groups, Combo: product.combo}
+	}
+
+	// Combos can name products further down the file, so they are checked once everything is read
+	for _, product := range rows {
+		if firstLine[strings.ToLower(product.name)] != product.line {
+			continue // Skipped above (no name or a duplicate)
+		}
+		for _, problem := range checkProduct(menu, product.name, menu[product.name]) {
+			report(product.line, "%s: %s", product.name, problem)
+		}
+	}
+
+	if len(problems) > 0 {
+		slices.SortStableFunc(problems, func(a, b LineError) int { return cmp.Compare(a.Line, b.Line) })
+		return nil, &ValidationError{Path: path, Errors: problems}
+	}
+	return menu, nil
+}
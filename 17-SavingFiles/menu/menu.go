@@ -0,0 +1,178 @@
+package menu
+
+import (
+	"17-SavingFiles/money"
+	"17-SavingFiles/tax"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// =====================
+// MENU TYPE
+// =====================
+
+// Currency is the currency the menu prices are in
+const Currency = "USD"
+
+// Categories are the sections a menu can have
+var Categories = []string{"starter", "main", "side", "dessert", "drink"}
+
+// Product is one thing on the menu
+type Product struct {
+	Price    money.Money // money.Money instead of float64 so totals are exact
+	TaxClass string      // e.g., tax.ClassFood, tax.ClassAlcohol
+	Category string      // One of Categories
+	SoldOut  bool        // Zero value false = available
+
+	Modifiers []ModifierGroup // Choices like size or extras (nil = none)
+	Combo     []string        // Products included when this is a combo (nil = not a combo)
+}
+
+// Menu maps a product name to its details (same idea as the 09-Maps menu)
+type Menu map[string]Product
+
+// Default returns the restaurant's starting menu
+func Default() Menu {
+	return Menu{
+		"soup":   {Price: money.MustParse("4.18", Currency), TaxClass: tax.ClassFood, Category: "starter"},
+		"rice":   {Price: money.MustParse("1.98", Currency), TaxClass: tax.ClassFood, Category: "side", Modifiers: riceOptions()},
+		"pie":    {Price: money.MustParse("5.50", Currency), TaxClass: tax.ClassFood, Category: "dessert"},
+		"salad":  {Price: money.MustParse("3.75", Currency), TaxClass: tax.ClassFood, Category: "starter"},
+		"coffee": {Price: money.MustParse("2.10", Currency), TaxClass: tax.ClassDrinks, Category: "drink", Modifiers: coffeeOptions()},
+		"wine":   {Price: money.MustParse("6.40", Currency), TaxClass: tax.ClassAlcohol, Category: "drink"},
+
+		// A combo has its own price, lower than its parts bought separately
+		"soup + rice combo": {Price: money.MustParse("5.50", Currency), TaxClass: tax.ClassFood, Category: "main", Combo: []string{"soup", "rice"}},
+	}
+}
+
+// riceOptions are the choices for rice: a size (required) and up to three extras
+func riceOptions() []ModifierGroup {
+	return []ModifierGroup{
+		{Name: "size", Required: true, Max: 1, Default: "regular", Options: []Modifier{
+			{Name: "regular", Delta: money.Zero(Currency)},
+			{Name: "large", Delta: money.MustParse("0.50", Currency)},
+			{Name: "extra large", Delta: money.MustParse("0.90", Currency)},
+		}},
+		{Name: "extras", Max: 3, Options: []Modifier{
+			{Name: "no onions", Delta: money.Zero(Currency)},
+			{Name: "extra egg", Delta: money.MustParse("0.75", Currency)},
+			{Name: "extra sauce", Delta: money.MustParse("0.30", Currency)},
+		}},
+	}
+}
+
+// coffeeOptions are the (optional) choices for coffee
+func coffeeOptions() []ModifierGroup {
+	return []ModifierGroup{
+		{Name: "milk", Max: 1, Options: []Modifier{
+			{Name: "oat milk", Delta: money.MustParse("0.40", Currency)},
+			{Name: "no milk", Delta: money.Zero(Currency)},
+		}},
+	}
+}
+
+// =====================
+// UPDATING THE MENU
+// =====================
+
+// UpdateMenu adds a product or changes its price
+// Maps are reference types, so the caller's menu is changed (see 10-PassByValue)
+// A new product gets the food tax class; use SetTaxClass to change it
+func UpdateMenu(menuToChange Menu, productName string, price money.Money) error {
+	if price.IsNegative() {
+		return fmt.Errorf("price of %s cannot be negative", productName)
+	}
+	if price.Currency() != Currency {
+		return fmt.Errorf("price of %s must be in %s", productName, Currency)
+	}
+	product, ok := menuToChange[productName]
+	if !ok {
+		product.TaxClass = tax.ClassFood
+		product.Category = "main"
+	}
+	product.Price = price
+	menuToChange[productName] = product
+	return nil
+}
+
+// SetTaxClass changes the tax class of a product on the menu
+func SetTaxClass(menuToChange Menu, productName string, class string) error {
+	product, ok := menuToChange[productName]
+	if !ok {
+		return fmt.Errorf("%q is not on the menu", productName)
+	}
+	product.TaxClass = class
+	menuToChange[productName] = product
+	return nil
+}
+
+// =====================
+// RECEIVER FUNCTIONS (METHODS)
+// =====================
+
+// Clone returns a copy, so the copy can change without affecting the original
+func (menu Menu) Clone() Menu {
+	clone := make(Menu, len(menu))
+	for name, product := range menu {
+		clone[name] = product
+	}
+	return clone
+}
+
+// Names returns the product names in alphabetical order
+// (looping over a map directly gives a random order)
+func (menu Menu) Names() []string {
+	names := make([]string, 0, len(menu))
+	for name := range menu {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Format returns the menu as aligned "name ... price" lines
+func (menu Menu) Format() string {
+	return menu.FormatWith(nil)
+}
+
+// FormatWith also shows each price in a second currency (nil = prices only)
+// A price that cannot be converted (no rate, stale rate) shows "n/a" with a warning at the end
+func (menu Menu) FormatWith(convert money.Converter) string {
+	formatted := ""
+	warnings := []string{}
+	for _, name := range menu.Names() {
+		product := menu[name]
+		soldOut := ""
+		if product.SoldOut {
+			soldOut = " - sold out"
+		}
+		if convert == nil {
+			formatted += fmt.Sprintf("%-20s %s%s\n", name+":", product.Price, soldOut)
+		} else {
+			shown := "n/a"
+			if converted, err := convert(product.Price); err != nil {
+				if !slices.Contains(warnings, err.Error()) {
+					warnings = append(warnings, err.Error())
+				}
+			} else {
+				shown = converted.String()
+			}
+			formatted += fmt.Sprintf("%-20s %-10s (%s)%s\n", name+":", product.Price, shown, soldOut)
+		}
+
+		// Combo parts and options are listed below the product
+		if len(product.Combo) > 0 {
+			formatted += "    with " + strings.Join(product.Combo, " + ") + "\n"
+		}
+		for _, group := range product.Modifiers {
+			formatted += "    " + describeGroup(group) + "\n"
+		}
+	}
+	for _, warning := range warnings {
+		formatted += "Warning: converted prices shown as n/a (" + warning + ")\n"
+	}
+	return formatted
+}
@@ -0,0 +1,242 @@
+package menu
+
+import (
+	"17-SavingFiles/money"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// =====================
+// MODIFIERS AND COMBOS
+// =====================
+
+// Modifier is one choice a customer can make, e.g., "extra large" or "no onions"
+type Modifier struct {
+	Name  string      `json:"name"`
+	Delta money.Money `json:"delta"` // Added to the product price (negative = cheaper)
+}
+
+// ModifierGroup is a set of choices for one product, e.g., "size" or "extras"
+type ModifierGroup struct {
+	Name     string     `json:"name"`
+	Required bool       `json:"required,omitempty"` // At least one option must be chosen
+	Max      int        `json:"max,omitempty"`      // Most options that can be chosen (0 = no limit)
+	Default  string     `json:"default,omitempty"`  // Chosen when a required group is left out
+	Options  []Modifier `json:"options"`
+}
+
+// find returns the option with that name
+func (group ModifierGroup) find(name string) (Modifier, bool) {
+	for _, option := range group.Options {
+		if strings.EqualFold(option.Name, name) {
+			return option, true
+		}
+	}
+	return Modifier{}, false
+}
+
+// Selection is one product as the customer asked for it
+type Selection struct {
+	Product   string
+	Modifiers []string // Names of the chosen modifiers
+}
+
+// ParseSelection reads "rice, extra large, no onions": the product, then its modifiers
+// The names are only checked against the menu by Menu.Resolve
+func ParseSelection(text string) Selection {
+	parts := strings.Split(text, ",")
+	selection := Selection{Product: strings.TrimSpace(parts[0])}
+	for _, part := range parts[1:] {
+		if part = strings.TrimSpace(part); part != "" {
+			selection.Modifiers = append(selection.Modifiers, part)
+		}
+	}
+	return selection
+}
+
+// Label is how the selection is shown on a bill: "rice (extra large, no onions)"
+func (selection Selection) Label() string {
+	if len(selection.Modifiers) == 0 {
+		return selection.Product
+	}
+	return selection.Product + " (" + strings.Join(selection.Modifiers, ", ") + ")"
+}
+
+// Resolve checks a selection against the menu and returns the price of one item
+// The returned selection has the defaults of required groups filled in and its
+// modifiers in menu order, so "no onions, large" and "large, no onions" are the same
+func (menu Menu) Resolve(selection Selection) (Selection, money.Money, error) {
+	product, ok := menu[selection.Product]
+	if !ok {
+		return Selection{}, money.Money{}, fmt.Errorf("%q is not on the menu", selection.Product)
+	}
+
+	// Find the group of every chosen modifier
+	chosen := make([][]string, len(product.Modifiers)) // Chosen option names per group
+	for _, name := range selection.Modifiers {
+		found := false
+		for i, group := range product.Modifiers {
+			option, ok := group.find(name)
+			if !ok {
+				continue
+			}
+			if slices.Contains(chosen[i], option.Name) {
+				return Selection{}, money.Money{}, fmt.Errorf("%s: %q chosen twice", selection.Product, option.Name)
+			}
+			chosen[i] = append(chosen[i], option.Name)
+			found = true
+			break
+		}
+		if !found {
+			return Selection{}, money.Money{}, fmt.Errorf("%s: unknown option %q%s", selection.Product, name, describeOptions(product))
+		}
+	}
+
+	resolved := Selection{Product: selection.Product}
+	price := product.Price
+	for i, group := range product.Modifiers {
+		if len(chosen[i]) == 0 && group.Required {
+			if group.Default == "" {
+				return Selection{}, money.Money{}, fmt.Errorf("%s: choose a %s (%s)", selection.Product, group.Name, optionNames(group))
+			}
+			// The default is matched like a typed option, so "Regular" picks "regular"
+			option, ok := group.find(group.Default)
+			if !ok {
+				return Selection{}, money.Money{}, fmt.Errorf("%s: default %s %q is not an option (%s)", selection.Product, group.Name, group.Default, optionNames(group))
+			}
+			chosen[i] = []string{option.Name}
+		}
+		if group.Max > 0 && len(chosen[i]) > group.Max {
+			return Selection{}, money.Money{}, fmt.Errorf("%s: at most %d %s allowed", selection.Product, group.Max, group.Name)
+		}
+
+		// Menu order, not the order they were typed in
+		for _, option := range group.Options {
+			if slices.Contains(chosen[i], option.Name) {
+				resolved.Modifiers = append(resolved.Modifiers, option.Name)
+				price = price.Add(option.Delta)
+			}
+		}
+	}
+	if price.IsNegative() {
+		return Selection{}, money.Money{}, fmt.Errorf("%s: options make the price negative", resolved.Label())
+	}
+	return resolved, price, nil
+}
+
+// =====================
+// DESCRIBING OPTIONS
+// =====================
+
+// optionNames lists a group's options with their price difference: "regular, large (+$0.50)"
+func optionNames(group ModifierGroup) string {
+	names := make([]string, len(group.Options))
+	for i, option := range group.Options {
+		names[i] = option.Name
+		switch {
+		case option.Delta.IsNegative():
+			names[i] += " (" + option.Delta.String() + ")"
+		case !option.Delta.IsZero():
+			names[i] += " (+" + option.Delta.String() + ")"
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// describeOptions lists every group of a product for error messages
+func describeOptions(product Product) string {
+	if len(product.Modifiers) == 0 {
+		return " (it has no options)"
+	}
+	groups := make([]string, len(product.Modifiers))
+	for i, group := range product.Modifiers {
+		groups[i] = group.Name + ": " + optionNames(group)
+	}
+	return " (" + strings.Join(groups, "; ") + ")"
+}
+
+// describeGroup is one menu line for a group: "size: regular, large (+$0.50) - pick 1, required, default regular"
+func describeGroup(group ModifierGroup) string {
+	rules := []string{}
+	switch {
+	case group.Max == 1 && group.Required:
+		rules = append(rules, "pick 1")
+	case group.Max > 0:
+		rules = append(rules, fmt.Sprintf("pick up to %d", group.Max))
+	}
+	if group.Required {
+		rule := "required"
+		if group.Default != "" {
+			rule += ", default " + group.Default
+		}
+		rules = append(rules, rule)
+	}
+	described := group.Name + ": " + optionNames(group)
+	if len(rules) > 0 {
+		described += " - " + strings.Join(rules, ", ")
+	}
+	return described
+}
+
+// checkProduct finds mistakes in a product's modifier groups and combo
+// (used when a menu is loaded from a file)
+func checkProduct(menu Menu, name string, product Product) []string {
+	problems := []string{}
+	groupNames := map[string]bool{}
+	options := map[string]bool{} // Option names must be unique over all groups to parse "rice, large"
+	for _, group := range product.Modifiers {
+		key := strings.ToLower(group.Name)
+		switch {
+		case group.Name == "":
+			problems = append(problems, "modifier group without a name")
+		case groupNames[key]:
+			problems = append(problems, fmt.Sprintf("modifier group %q appears twice", group.Name))
+		}
+		groupNames[key] = true
+
+		if len(group.Options) == 0 {
+			problems = append(problems, fmt.Sprintf("modifier group %q has no options", group.Name))
+		}
+		if group.Max < 0 {
+			problems = append(problems, fmt.Sprintf("modifier group %q: max cannot be negative", group.Name))
+		}
+		for _, option := range group.Options {
+			key := strings.ToLower(option.Name)
+			switch {
+			case option.Name == "" || strings.Contains(option.Name, ","):
+				problems = append(problems, fmt.Sprintf("modifier group %q: option name %q is empty or has a comma", group.Name, option.Name))
+			case options[key]:
+				problems = append(problems, fmt.Sprintf("option %q appears twice", option.Name))
+			}
+			options[key] = true
+			if option.Delta.Currency() != "" && option.Delta.Currency() != Currency {
+				problems = append(problems, fmt.Sprintf("option %q must be priced in %s", option.Name, Currency))
+			}
+		}
+		if group.Default != "" {
+			if _, ok := group.find(group.Default); !ok {
+				problems = append(problems, fmt.Sprintf("modifier group %q: default %q is not one of its options", group.Name, group.Default))
+			}
+		}
+	}
+
+	for _, part := range product.Combo {
+		included, ok := menu[part]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("combo part %q is not on the menu", part))
+		case part == name || len(included.Combo) > 0:
+			problems = append(problems, fmt.Sprintf("combo part %q cannot be a combo itself", part))
+		}
+	}
+	return problems
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// menu.ParseSelection("rice, large")   -> Selection{Product: "rice", Modifiers: ["large"]}
+// m.Resolve(selection)                 -> defaults filled in, price of one item
+// selection.Label()                    -> "rice (large)"
+// Product.Combo                        -> parts of a combo; the combo has its own Price
@@ -0,0 +1,75 @@
+package menu
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
+
+// =====================
+// LIVE MENU
+// =====================
+
+// Live holds the current menu so it can be swapped while the app runs
+// A mutex (mutual exclusion lock) stops two goroutines using it at the same time
+type Live struct {
+	mu      sync.RWMutex
+	current Menu
+}
+
+// NewLive creates a Live menu
+func NewLive(start Menu) *Live {
+	return &Live{current: start}
+}
+
+// Get returns the current menu
+// Treat it as read-only: Set a changed Clone instead of editing it
+func (live *Live) Get() Menu {
+	live.mu.RLock()
+	defer live.mu.RUnlock()
+	return live.current
+}
+
+// Set replaces the current menu
+func (live *Live) Set(menu Menu) {
+	live.mu.Lock()
+	defer live.mu.Unlock()
+	live.current = menu
+}
+
+// =====================
+// HOT RELOAD
+// =====================
+
+// Watch checks the file every interval and reloads it when it changed on disk
+// onReload gets the new menu, or the validation error (the old menu stays in use)
+// It runs until ctx is cancelled, so start it with: go menu.Watch(ctx, ...)
+func Watch(ctx context.Context, path string, interval time.Duration, onReload func(Menu, error)) {
+	lastChange := modified(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			change := modified(path)
+			if change.Equal(lastChange) {
+				continue
+			}
+			lastChange = change
+			onReload(LoadFile(path))
+		}
+	}
+}
+
+// modified returns when the file last changed (zero time if it is missing)
+func modified(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,461 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// =====================
+// WHY NOT float64?
+// =====================
+
+// 0.1 + 0.2 != 0.3 with float64, so adding prices slowly collects rounding errors
+// Money stores a whole number of "minor units" (cents for USD/EUR) instead:
+// $4.18 is stored as 418 and every calculation is exact integer math
+
+// =====================
+// CURRENCIES
+// =====================
+
+// currencyInfo describes how a currency is written
+type currencyInfo struct {
+	decimals int    // Digits after the decimal point (USD 2, JPY 0, KWD 3)
+	symbol   string // Printed before the amount
+}
+
+// currencies lists the supported ISO 4217 codes
+var currencies = map[string]currencyInfo{
+	"USD": {decimals: 2, symbol: "$"},
+	"EUR": {decimals: 2, symbol: "€"},
+	"GBP": {decimals: 2, symbol: "£"},
+	"CHF": {decimals: 2, symbol: "CHF "},
+	"CAD": {decimals: 2, symbol: "CA$"},
+	"AUD": {decimals: 2, symbol: "A$"},
+	"SEK": {decimals: 2, symbol: "SEK "},
+	"AED": {decimals: 2, symbol: "AED "},
+	"EGP": {decimals: 2, symbol: "E£"},
+	"MAD": {decimals: 2, symbol: "MAD "},
+	"TRY": {decimals: 2, symbol: "₺"},
+	"INR": {decimals: 2, symbol: "₹"},
+	"CNY": {decimals: 2, symbol: "CN¥"},
+	"JPY": {decimals: 0, symbol: "¥"},
+	"KRW": {decimals: 0, symbol: "₩"},
+	"KWD": {decimals: 3, symbol: "KWD "},
+	"BHD": {decimals: 3, symbol: "BHD "},
+}
+
+// ErrCurrencyMismatch is returned when two amounts in different currencies meet
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+// ErrOverflow is returned when a result does not fit in an int64 of minor units
+var ErrOverflow = errors.New("money: amount too large")
+
+// Decimals returns how many minor-unit digits a currency has
+func Decimals(currency string) (int, error) {
+	info, ok := currencies[currency]
+	if !ok {
+		return 0, fmt.Errorf("money: unknown currency %q", currency)
+	}
+	return info.decimals, nil
+}
+
+// =====================
+// STRUCT DEFINITION
+// =====================
+
+// Money is an exact amount in one currency
+// The zero value has no currency and only works with Add/Sub as "nothing yet"
+type Money struct {
+	minor    int64  // Amount in minor units (e.g., cents)
+	currency string // ISO 4217 code (e.g., "USD")
+}
+
+// =====================
+// CONSTRUCTOR FUNCTIONS
+// =====================
+
+// New creates an amount from minor units: New(418, "USD") is $4.18
+func New(minor int64, currency string) Money {
+	return Money{minor: minor, currency: currency}
+}
+
+// Zero returns 0 in the given currency
+func Zero(currency string) Money {
+	return Money{currency: currency}
+}
+
+// Parse reads a decimal string such as "4.18", "-0.5", "1,250.00" or "-$4.18"
+// It never goes through float64, and it refuses more decimals than the currency has
+func Parse(text string, currency string) (Money, error) {
+	decimals, err := Decimals(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	original := text
+	text = strings.TrimSpace(text)
+
+	// The sign may come before the symbol, as String writes it ("-$4.18"), or after it ("$-4.18")
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+	text = strings.TrimSpace(strings.TrimPrefix(text, strings.TrimSpace(currencies[currency].symbol)))
+	if !negative {
+		negative = strings.HasPrefix(text, "-")
+		text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+	}
+	text = strings.ReplaceAll(text, ",", "") // Thousands separators
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("money: %q is not an amount", original)
+	}
+	if len(fraction) > decimals {
+		return Money{}, fmt.Errorf("money: %q has more than %d decimals for %s", original, decimals, currency)
+	}
+	fraction += strings.Repeat("0", decimals-len(fraction)) // "4.1" -> "4.10"
+
+	digits := whole + fraction
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return Money{}, fmt.Errorf("money: %q is not an amount", original)
+		}
+	}
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("money: %q is too large", original)
+	}
+
+	if negative {
+		minor = -minor
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// MustParse is Parse for amounts written in code; it panics on a typo
+func MustParse(text string, currency string) Money {
+	amount, err := Parse(text, currency)
+	if err != nil {
+		panic(err)
+	}
+	return amount
+}
+
+// =====================
+// ACCESSORS
+// =====================
+
+// Minor returns the amount in minor units (cents)
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+// Currency returns the ISO 4217 code
+func (m Money) Currency() string {
+	return m.currency
+}
+
+// IsZero reports whether the amount is 0
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsNegative reports whether the amount is below 0
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// SameCurrency reports whether both amounts can be added together
+// A zero value without currency matches anything
+func (m Money) SameCurrency(other Money) bool {
+	return m.currency == other.currency || m.currency == "" || other.currency == ""
+}
+
+// Cmp returns -1, 0 or 1 when m is less than, equal to or more than other
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+	switch {
+	case m.minor < other.minor:
+		return -1
+	case m.minor > other.minor:
+		return 1
+	}
+	return 0
+}
+
+// =====================
+// ARITHMETIC
+// =====================
+
+// Add returns m + other
+// It panics on different currencies: check SameCurrency first for user input
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return Money{minor: m.minor + other.minor, currency: m.pick(other)}
+}
+
+// Sub returns m - other (panics on different currencies, like Add)
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return Money{minor: m.minor - other.minor, currency: m.pick(other)}
+}
+
+// Neg returns -m
+func (m Money) Neg() Money {
+	return Money{minor: -m.minor, currency: m.currency}
+}
+
+// Mul multiplies by a quantity (exact)
+// It returns ErrOverflow instead of silently wrapping around
+func (m Money) Mul(quantity int64) (Money, error) {
+	product, ok := multiply(m.minor, quantity)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %s x %d", ErrOverflow, m, quantity)
+	}
+	return Money{minor: product, currency: m.currency}, nil
+}
+
+// MulFraction returns m * numerator / denominator, rounded with banker's rounding
+// e.g., 21% VAT is MulFraction(21, 100)
+// A product too large for int64 is worked out with math/big; only a result that
+// does not fit returns ErrOverflow
+func (m Money) MulFraction(numerator int64, denominator int64) (Money, error) {
+	if denominator == 0 {
+		panic("money: division by zero")
+	}
+	if product, ok := multiply(m.minor, numerator); ok {
+		return Money{minor: RoundHalfEven(product, denominator), currency: m.currency}, nil
+	}
+
+	// Same rounding as RoundHalfEven, on big integers
+	a := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(numerator))
+	b := big.NewInt(denominator)
+	if b.Sign() < 0 {
+		a.Neg(a)
+		b.Neg(b)
+	}
+	quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	doubled := new(big.Int).Abs(remainder)
+	doubled.Lsh(doubled, 1)
+	if cmp := doubled.Cmp(b); cmp > 0 || cmp == 0 && quotient.Bit(0) == 1 {
+		if a.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	if !quotient.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s x %d/%d", ErrOverflow, m, numerator, denominator)
+	}
+	return Money{minor: quotient.Int64(), currency: m.currency}, nil
+}
+
+// multiply returns a * b and false when it does not fit in an int64
+func multiply(a int64, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return product, true
+}
+
+// Div divides with banker's rounding (half a cent rounds to the even cent)
+// Use Allocate when the parts must add back up to the total
+func (m Money) Div(divisor int64) Money {
+	return Money{minor: RoundHalfEven(m.minor, divisor), currency: m.currency}
+}
+
+// RoundHalfEven divides a by b and rounds exact halves to the even result
+// (banker's rounding: 0.5 -> 0, 1.5 -> 2, 2.5 -> 2), so rounding has no upward bias
+func RoundHalfEven(a int64, b int64) int64 {
+	if b == 0 {
+		panic("money: division by zero")
+	}
+	if b < 0 {
+		a, b = -a, -b
+	}
+
+	quotient := a / b
+	remainder := a % b
+	if remainder < 0 {
+		remainder = -remainder
+	}
+
+	// Compare the remainder with half of b (times 2 to stay in integers)
+	switch doubled := remainder * 2; {
+	case doubled > b, doubled == b && quotient%2 != 0:
+		if a < 0 {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return quotient
+}
+
+// Allocate splits m into n parts that always add up to m exactly
+// The first parts receive the leftover minor units: $10.00 / 3 = 3.34, 3.33, 3.33
+func (m Money) Allocate(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, errors.New("money: cannot allocate to fewer than 1 part")
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.AllocateRatios(ratios)
+}
+
+// AllocateRatios splits m by ratios (e.g., 2:1 -> two thirds and one third)
+// The leftover minor units go one by one to the first parts
+// Ratios so large that the shares cannot be worked out in an int64 return ErrOverflow
+func (m Money) AllocateRatios(ratios []int64) ([]Money, error) {
+	total := int64(0)
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, errors.New("money: negative ratio")
+		}
+		if total > math.MaxInt64-ratio {
+			return nil, fmt.Errorf("%w: ratios add up to more than %d", ErrOverflow, int64(math.MaxInt64))
+		}
+		total += ratio
+	}
+	if total == 0 {
+		return nil, errors.New("money: ratios add up to zero")
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.minor
+	for i, ratio := range ratios {
+		product, ok := multiply(m.minor, ratio)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s x %d", ErrOverflow, m, ratio)
+		}
+		share := product / total // Truncates towards zero
+		parts[i] = Money{minor: share, currency: m.currency}
+		remainder -= share
+	}
+
+	// Hand out what truncation left over, one minor unit at a time
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].minor += step
+		remainder -= step
+	}
+	return parts, nil
+}
+
+// =====================
+// FORMATTING
+// =====================
+
+// String formats the amount with its symbol: "$4.18", "-€0.50", "¥500"
+func (m Money) String() string {
+	info, ok := currencies[m.currency]
+	if !ok {
+		return m.Amount() + " " + m.currency
+	}
+	if m.minor < 0 {
+		return "-" + info.symbol + strings.TrimPrefix(m.Amount(), "-")
+	}
+	return info.symbol + m.Amount()
+}
+
+// Amount formats only the number with the currency's decimals: "4.18"
+func (m Money) Amount() string {
+	decimals := currencies[m.currency].decimals
+	minor := m.minor
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	digits := strconv.FormatUint(uint64(minor), 10) // uint64: -MinInt64 does not fit in an int64
+	if decimals == 0 {
+		return sign + digits
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	split := len(digits) - decimals
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// MarshalText saves Money as "USD 4.18" (used by encoding/json and friends)
+// The zero Money (no currency, as in an empty struct) is saved as ""
+func (m Money) MarshalText() ([]byte, error) {
+	if m.currency == "" {
+		if m.minor != 0 {
+			return nil, fmt.Errorf("money: %s has no currency", m.Amount())
+		}
+		return []byte{}, nil
+	}
+	return []byte(m.currency + " " + m.Amount()), nil
+}
+
+// UnmarshalText reads the "USD 4.18" form back ("" is the zero Money)
+// Uses pointer receiver (*Money) because it fills in the value
+func (m *Money) UnmarshalText(text []byte) error {
+	if strings.TrimSpace(string(text)) == "" {
+		*m = Money{}
+		return nil
+	}
+	currency, amount, found := strings.Cut(strings.TrimSpace(string(text)), " ")
+	if !found {
+		return fmt.Errorf("money: %q should look like \"USD 4.18\"", text)
+	}
+	parsed, err := Parse(amount, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// =====================
+// CONVERTING
+// =====================
+
+// Converter turns an amount into another currency (see the exchange package)
+// Formatting code takes a Converter so it does not need to know about rate tables
+type Converter func(Money) (Money, error)
+
+// =====================
+// PRIVATE METHODS
+// =====================
+
+// mustMatch panics when the currencies differ
+func (m Money) mustMatch(other Money) {
+	if !m.SameCurrency(other) {
+		panic(fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency))
+	}
+}
+
+// pick returns whichever currency is set (the zero value has none)
+func (m Money) pick(other Money) string {
+	if m.currency != "" {
+		return m.currency
+	}
+	return other.currency
+}
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// money.MustParse("4.18", "USD") -> $4.18 stored as 418 cents
+// price.Mul(3)                   -> exact multiplication (error on overflow)
+// total.Div(3)                   -> banker's rounding
+// total.Allocate(3)              -> split with no lost cents
@@ -0,0 +1,425 @@
+package promo
+
+import (
+	"17-SavingFiles/money"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================
+// ORDERS AND RESULTS
+// =====================
+
+// Line is one ordered product
+// A product ordered with different options ("rice (large)", "rice (regular)")
+// is on several lines; promotions match the product, not the line
+type Line struct {
+	Name     string      // Unique per line
+	Product  string      // Menu product (empty = same as Name)
+	Price    money.Money // Price of one item
+	Quantity int
+}
+
+// product is the menu product on the line
+func (line Line) product() string {
+	if line.Product == "" {
+		return line.Name
+	}
+	return line.Product
+}
+
+// Order is everything the engine needs to pick promotions
+type Order struct {
+	Lines []Line
+	At    time.Time // When the order is placed (for happy hours)
+	Codes []string  // Coupon codes given by the customer
+}
+
+// Applied is one promotion used on an order
+type Applied struct {
+	Name        string
+	Amount      money.Money            // Total discount (positive)
+	Explanation string                 // Shown on the receipt
+	PerItem     map[string]money.Money // How the discount is spread over the items (for tax)
+}
+
+// =====================
+// PICKING THE BEST COMBINATION
+// =====================
+
+// Best returns the combination of promotions that saves the customer the most
+// Promotions in the same group, or marked exclusive, are never combined,
+// and every ordered unit can only be discounted by one item-level promotion
+// All combinations of the eligible rules are tried, which is fine for menus
+// with a handful of promotions running at the same time
+func (engine *Engine) Best(order Order) []Applied {
+	eligible := []promotion{}
+	for _, candidate := range engine.promotions {
+		if engine.conditionsMet(candidate, order) {
+			eligible = append(eligible, candidate)
+		}
+	}
+
+	best := []Applied{}
+	bestTotal := money.Zero(engine.currency)
+	engine.search(order, eligible, 0, []promotion{}, &best, &bestTotal)
+	return best
+}
+
+// search tries every allowed subset of eligible[index:] added to chosen
+func (engine *Engine) search(order Order, eligible []promotion, index int, chosen []promotion, best *[]Applied, bestTotal *money.Money) {
+	if index == len(eligible) {
+		applied := engine.apply(order, chosen)
+		total := money.Zero(engine.currency)
+		for _, discount := range applied {
+			total = total.Add(discount.Amount)
+		}
+		// Strictly better only, so fewer promotions win a tie
+		if total.Cmp(*bestTotal) > 0 {
+			*best, *bestTotal = applied, total
+		}
+		return
+	}
+
+	// Without eligible[index]
+	engine.search(order, eligible, index+1, chosen, best, bestTotal)
+
+	// With eligible[index], if it does not conflict with what is chosen
+	candidate := eligible[index]
+	for _, other := range chosen {
+		if candidate.Exclusive || other.Exclusive || (candidate.Group != "" && candidate.Group == other.Group) {
+			return
+		}
+	}
+	engine.search(order, eligible, index+1, append(slices.Clone(chosen), candidate), best, bestTotal)
+}
+
+// =====================
+// APPLYING PROMOTIONS
+// =====================
+
+// apply runs the chosen promotions: item-level first (in file order), then order-level
+func (engine *Engine) apply(order Order, chosen []promotion) []Applied {
+	// Units still available for item-level discounts
+	units := newPool(order.Lines)
+
+	// Value of each item after discounts so far (order-level discounts are spread over it)
+	value := map[string]money.Money{}
+	for _, line := range order.Lines {
+		lineTotal, err := line.Price.Mul(int64(line.Quantity))
+		if err != nil {
+			return nil // Too large to price: no promotions rather than wrong ones
+		}
+		value[line.Name] = value[line.Name].Add(lineTotal)
+	}
+
+	applied := []Applied{}
+	for _, p := range chosen {
+		if !p.itemLevel() {
+			continue
+		}
+		if discount, ok := engine.applyItems(p, units); ok {
+			for name, amount := range discount.PerItem {
+				value[name] = value[name].Sub(amount)
+			}
+			applied = append(applied, discount)
+		}
+	}
+	for _, p := range chosen {
+		if p.itemLevel() {
+			continue
+		}
+		if discount, ok := engine.applyOrder(p, order.Lines, value); ok {
+			for name, amount := range discount.PerItem {
+				value[name] = value[name].Sub(amount)
+			}
+			applied = append(applied, discount)
+		}
+	}
+	return applied
+}
+
+// pool is what is left of an order for item-level promotions
+type pool struct {
+	remaining map[string]int         // Units left per line
+	prices    map[string]money.Money // Price of one unit per line
+	lines     map[string][]string    // Line names per product, in order
+}
+
+// newPool puts every ordered unit in the pool
+func newPool(lines []Line) pool {
+	units := pool{remaining: map[string]int{}, prices: map[string]money.Money{}, lines: map[string][]string{}}
+	for _, line := range lines {
+		if _, seen := units.remaining[line.Name]; !seen {
+			units.lines[line.product()] = append(units.lines[line.product()], line.Name)
+		}
+		units.remaining[line.Name] += line.Quantity
+		units.prices[line.Name] = line.Price
+	}
+	return units
+}
+
+// count is how many units of a product are left
+func (units pool) count(product string) int {
+	total := 0
+	for _, name := range units.lines[product] {
+		total += units.remaining[name]
+	}
+	return total
+}
+
+// take removes the most expensive unit of a product left and returns its line
+func (units pool) take(product string) (string, bool) {
+	best := ""
+	for _, name := range units.lines[product] {
+		if units.remaining[name] > 0 && (best == "" || units.prices[name].Cmp(units.prices[best]) > 0) {
+			best = name
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+	units.remaining[best]--
+	return best, true
+}
+
+// applyItems applies an item-level promotion to the units still available
+func (engine *Engine) applyItems(p promotion, units pool) (Applied, bool) {
+	discount := Applied{Name: p.Name, Amount: money.Zero(engine.currency), PerItem: map[string]money.Money{}}
+
+	switch p.Kind {
+	case KindCombo:
+		// Make combos from the most expensive units first, as long as they save something
+		count := 0
+		for {
+			taken := []string{}
+			normal := money.Zero(engine.currency)
+			for _, product := range p.Items {
+				name, ok := units.take(product)
+				if !ok {
+					break
+				}
+				taken = append(taken, name)
+				normal = normal.Add(units.prices[name])
+			}
+			saving := normal.Sub(p.price)
+			if len(taken) < len(p.Items) || saving.IsZero() || saving.IsNegative() {
+				for _, name := range taken {
+					units.remaining[name]++ // Not a combo after all
+				}
+				break
+			}
+
+			// Spread the saving over the combo items by price, for tax
+			ratios := make([]int64, len(taken))
+			for i, name := range taken {
+				ratios[i] = units.prices[name].Minor()
+			}
+			shares, _ := saving.AllocateRatios(ratios)
+			for i, name := range taken {
+				discount.PerItem[name] = discount.PerItem[name].Add(shares[i])
+			}
+			discount.Amount = discount.Amount.Add(saving)
+			count++
+		}
+		if count == 0 {
+			return Applied{}, false
+		}
+		discount.Explanation = fmt.Sprintf("%d x %s for %s each (save %s)", count, strings.Join(p.Items, " + "), p.price, discount.Amount)
+
+	case KindBOGO:
+		product := p.Items[0]
+		groups := units.count(product) / (p.Buy + p.Get)
+		if groups == 0 {
+			return Applied{}, false
+		}
+		// take gives the most expensive units first, so the cheapest ones are free
+		taken := make([]string, groups*(p.Buy+p.Get))
+		for i := range taken {
+			taken[i], _ = units.take(product)
+		}
+		free := groups * p.Get
+		for _, name := range taken[len(taken)-free:] {
+			discount.PerItem[name] = discount.PerItem[name].Add(units.prices[name])
+			discount.Amount = discount.Amount.Add(units.prices[name])
+		}
+		discount.Explanation = fmt.Sprintf("buy %d get %d free: %d x %s free", p.Buy, p.Get, free, product)
+
+	case KindPercent, KindFixed:
+		described := []string{}
+		for _, product := range p.Items {
+			for _, name := range units.lines[product] {
+				count := units.remaining[name]
+				if count == 0 {
+					continue
+				}
+				units.remaining[name] = 0
+				lineTotal, err := units.prices[name].Mul(int64(count))
+				if err != nil {
+					return Applied{}, false
+				}
+
+				var off money.Money
+				if p.Kind == KindPercent {
+					off, err = lineTotal.MulFraction(p.percent, 10000)
+				} else {
+					off, err = p.amount.Mul(int64(count))
+				}
+				if err != nil {
+					return Applied{}, false
+				}
+				if off.Cmp(lineTotal) > 0 {
+					off = lineTotal // A fixed amount off never goes below zero
+				}
+				discount.Amount = discount.Amount.Add(off)
+				discount.PerItem[name] = off
+				described = append(described, fmt.Sprintf("%d x %s", count, name))
+			}
+		}
+		if discount.Amount.IsZero() {
+			return Applied{}, false
+		}
+		discount.Explanation = describeOff(p) + " " + strings.Join(described, ", ")
+	}
+
+	discount.Explanation += describeConditions(p)
+	return discount, true
+}
+
+// applyOrder applies an order-level promotion to what is left of the order
+func (engine *Engine) applyOrder(p promotion, lines []Line, value map[string]money.Money) (Applied, bool) {
+	names := []string{}
+	left := money.Zero(engine.currency)
+	for _, line := range lines {
+		if !slices.Contains(names, line.Name) {
+			names = append(names, line.Name)
+			left = left.Add(value[line.Name])
+		}
+	}
+	if left.IsZero() || left.IsNegative() {
+		return Applied{}, false
+	}
+
+	amount := p.amount
+	if p.Kind == KindPercent {
+		var err error
+		if amount, err = left.MulFraction(p.percent, 10000); err != nil {
+			return Applied{}, false
+		}
+	}
+	if amount.Cmp(left) > 0 {
+		amount = left // A coupon never makes the order negative
+	}
+	if amount.IsZero() {
+		return Applied{}, false
+	}
+
+	// Spread over the items by their remaining value, for tax
+	ratios := make([]int64, len(names))
+	for i, name := range names {
+		ratios[i] = max(value[name].Minor(), 0)
+	}
+	shares, err := amount.AllocateRatios(ratios)
+	if err != nil {
+		return Applied{}, false
+	}
+	perItem := map[string]money.Money{}
+	for i, name := range names {
+		perItem[name] = shares[i]
+	}
+
+	return Applied{
+		Name:        p.Name,
+		Amount:      amount,
+		Explanation: describeOff(p) + " the order" + describeConditions(p),
+		PerItem:     perItem,
+	}, true
+}
+
+// =====================
+// CONDITIONS
+// =====================
+
+// conditionsMet checks every condition of a promotion against the order
+func (engine *Engine) conditionsMet(p promotion, order Order) bool {
+	conditions := p.Conditions
+
+	if conditions.Code != "" && !slices.ContainsFunc(order.Codes, func(code string) bool {
+		return strings.EqualFold(code, conditions.Code)
+	}) {
+		return false
+	}
+
+	quantities := map[string]int{}
+	subtotal := money.Zero(engine.currency)
+	for _, line := range order.Lines {
+		quantities[line.product()] += line.Quantity
+		lineTotal, err := line.Price.Mul(int64(line.Quantity))
+		if err != nil {
+			return false
+		}
+		subtotal = subtotal.Add(lineTotal)
+	}
+	for _, name := range conditions.Items {
+		if quantities[name] == 0 || quantities[name] < conditions.MinQuantity {
+			return false
+		}
+	}
+	if subtotal.Cmp(p.minTotal) < 0 {
+		return false
+	}
+
+	if p.days != nil && !p.days[order.At.Weekday()] {
+		return false
+	}
+	if p.from >= 0 {
+		minute := order.At.Hour()*60 + order.At.Minute()
+		if p.from <= p.to && (minute < p.from || minute >= p.to) {
+			return false
+		}
+		if p.from > p.to && minute < p.from && minute >= p.to { // Window wraps past midnight
+			return false
+		}
+	}
+	return true
+}
+
+// =====================
+// EXPLANATIONS
+// =====================
+
+// describeOff says what the discount is ("20% off", "$2.00 off")
+func describeOff(p promotion) string {
+	if p.Kind == KindPercent {
+		return strconv.FormatFloat(float64(p.percent)/100, 'f', -1, 64) + "% off"
+	}
+	if len(p.Items) > 0 {
+		return p.amount.String() + " off each of"
+	}
+	return p.amount.String() + " off"
+}
+
+// describeConditions lists the conditions that were met, in brackets
+func describeConditions(p promotion) string {
+	parts := []string{}
+	conditions := p.Conditions
+	if conditions.From != "" {
+		parts = append(parts, conditions.From+"-"+conditions.To)
+	}
+	if len(conditions.Days) > 0 {
+		parts = append(parts, strings.Join(conditions.Days, "/"))
+	}
+	if conditions.MinTotal != "" {
+		parts = append(parts, "orders from "+p.minTotal.String())
+	}
+	if conditions.Code != "" {
+		parts = append(parts, "coupon "+conditions.Code)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,194 @@
+package promo
+
+import (
+	"17-SavingFiles/money"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =====================
+// RULE TYPES
+// =====================
+
+// Kind is what a promotion does
+type Kind string
+
+const (
+	KindPercent Kind = "percent" // Percentage off listed items, or off the whole order
+	KindFixed   Kind = "fixed"   // Amount off each listed item, or off the whole order (coupons)
+	KindCombo   Kind = "combo"   // Listed items together for one price
+	KindBOGO    Kind = "bogo"    // Buy N get M free
+)
+
+// Conditions must all hold for a promotion to apply
+type Conditions struct {
+	Items       []string `json:"items"`        // Every listed item must be ordered...
+	MinQuantity int      `json:"min_quantity"` // ...at least this many times each
+	From        string   `json:"from"`         // Time of day "HH:MM" (inclusive)
+	To          string   `json:"to"`           // Time of day "HH:MM" (exclusive); may wrap past midnight
+	Days        []string `json:"days"`         // "mon".."sun"; empty = every day
+	MinTotal    string   `json:"min_total"`    // Order subtotal must be at least this
+	Code        string   `json:"code"`         // Coupon code the customer must give
+}
+
+// Rule is one promotion as written in the promotions file
+type Rule struct {
+	Name       string     `json:"name"`
+	Kind       Kind       `json:"kind"`
+	Items      []string   `json:"items"`     // Items the discount applies to (empty = whole order)
+	Percent    string     `json:"percent"`   // KindPercent: "20" or "12.5"
+	Amount     string     `json:"amount"`    // KindFixed: "2.00"
+	Price      string     `json:"price"`     // KindCombo: price of one combo
+	Buy        int        `json:"buy"`       // KindBOGO: pay for this many...
+	Get        int        `json:"get"`       // ...and get this many free
+	Group      string     `json:"group"`     // At most one promotion per group is used
+	Exclusive  bool       `json:"exclusive"` // Cannot be combined with any other promotion
+	Conditions Conditions `json:"conditions"`
+}
+
+// promotion is a Rule with its text fields parsed
+type promotion struct {
+	Rule
+	percent  int64 // Basis points (2000 = 20%)
+	amount   money.Money
+	price    money.Money
+	minTotal money.Money
+	from     int // Minutes after midnight, -1 = no time window
+	to       int
+	days     map[time.Weekday]bool
+}
+
+// itemLevel reports whether the promotion targets specific items
+// (order-level promotions work on whatever is left after item-level ones)
+func (p promotion) itemLevel() bool {
+	return len(p.Items) > 0
+}
+
+// =====================
+// LOADING RULES
+// =====================
+
+// Engine evaluates promotion rules against orders
+type Engine struct {
+	currency   string
+	promotions []promotion
+}
+
+// NewEngine checks and prepares rules for orders in one currency
+func NewEngine(rules []Rule, currency string) (*Engine, error) {
+	engine := &Engine{currency: currency}
+	for i, rule := range rules {
+		parsed, err := parseRule(rule, currency)
+		if err != nil {
+			return nil, fmt.Errorf("promotion %d (%s): %w", i+1, rule.Name, err)
+		}
+		engine.promotions = append(engine.promotions, parsed)
+	}
+	return engine, nil
+}
+
+// LoadFile reads rules from a JSON file: {"promotions": [ ... ]}
+func LoadFile(path string, currency string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Promotions []Rule `json:"promotions"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewEngine(file.Promotions, currency)
+}
+
+// parseRule validates a rule and parses its amounts, times and days
+func parseRule(rule Rule, currency string) (promotion, error) {
+	parsed := promotion{Rule: rule, from: -1, to: -1}
+	var err error
+
+	switch rule.Kind {
+	case KindPercent:
+		if parsed.percent, err = parsePercent(rule.Percent); err != nil {
+			return parsed, err
+		}
+	case KindFixed:
+		if parsed.amount, err = money.Parse(rule.Amount, currency); err != nil {
+			return parsed, err
+		}
+	case KindCombo:
+		if len(rule.Items) < 2 {
+			return parsed, fmt.Errorf("a combo needs at least 2 items")
+		}
+		if parsed.price, err = money.Parse(rule.Price, currency); err != nil {
+			return parsed, err
+		}
+	case KindBOGO:
+		if len(rule.Items) != 1 || rule.Buy < 1 || rule.Get < 1 {
+			return parsed, fmt.Errorf("buy-get needs exactly 1 item, buy >= 1 and get >= 1")
+		}
+	default:
+		return parsed, fmt.Errorf("unknown kind %q (use percent, fixed, combo or bogo)", rule.Kind)
+	}
+
+	conditions := rule.Conditions
+	parsed.minTotal = money.Zero(currency)
+	if conditions.MinTotal != "" {
+		if parsed.minTotal, err = money.Parse(conditions.MinTotal, currency); err != nil {
+			return parsed, err
+		}
+	}
+	if conditions.From != "" || conditions.To != "" {
+		if parsed.from, err = parseClock(conditions.From); err != nil {
+			return parsed, err
+		}
+		if parsed.to, err = parseClock(conditions.To); err != nil {
+			return parsed, err
+		}
+	}
+	if len(conditions.Days) > 0 {
+		parsed.days = map[time.Weekday]bool{}
+		for _, day := range conditions.Days {
+			weekday, ok := weekdays[strings.ToLower(day)]
+			if !ok {
+				return parsed, fmt.Errorf("unknown day %q (use mon..sun)", day)
+			}
+			parsed.days[weekday] = true
+		}
+	}
+	return parsed, nil
+}
+
+// weekdays maps short day names to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parsePercent reads "20" or "12.5" as basis points
+func parsePercent(text string) (int64, error) {
+	text = strings.TrimSuffix(strings.TrimSpace(text), "%")
+	whole, fraction, _ := strings.Cut(text, ".")
+	if len(fraction) > 2 {
+		return 0, fmt.Errorf("percent %q has more than 2 decimals", text)
+	}
+	basisPoints, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", 2-len(fraction)), 10, 64)
+	if err != nil || basisPoints <= 0 || basisPoints > 10000 {
+		return 0, fmt.Errorf("%q is not a percentage between 0 and 100", text)
+	}
+	return basisPoints, nil
+}
+
+// parseClock reads "HH:MM" as minutes after midnight
+func parseClock(text string) (int, error) {
+	moment, err := time.Parse("15:04", strings.TrimSpace(text))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", text)
+	}
+	return moment.Hour()*60 + moment.Minute(), nil
+}
@@ -0,0 +1,142 @@
+package tax
+
+import (
+	"17-SavingFiles/money"
+	"fmt"
+	"sort"
+)
+
+// =====================
+// CALCULATION MODE
+// =====================
+
+// Mode decides where tax is rounded
+type Mode int
+
+const (
+	PerLine Mode = iota // Round the tax of every line, then add them up
+	PerBill             // Add up lines per rate, then round once per rate
+)
+
+// String returns "line" or "bill", the names ParseMode reads
+func (mode Mode) String() string {
+	if mode == PerBill {
+		return "bill"
+	}
+	return "line"
+}
+
+// ParseMode reads "line" or "bill"
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "line", "":
+		return PerLine, nil
+	case "bill":
+		return PerBill, nil
+	}
+	return 0, fmt.Errorf("unknown tax mode %q (use line or bill)", name)
+}
+
+// =====================
+// INPUT AND RESULT TYPES
+// =====================
+
+// Line is one amount to tax (e.g., "2 x soup" costing $8.36)
+type Line struct {
+	Name   string
+	Class  string
+	Amount money.Money // As written on the menu (with or without tax)
+}
+
+// LineTax is the tax on one line
+type LineTax struct {
+	Line
+	Rate  Rate
+	Net   money.Money // Without tax
+	Tax   money.Money
+	Gross money.Money // With tax
+}
+
+// RateTotal groups everything taxed at one rate (one row of the receipt breakdown)
+type RateTotal struct {
+	Rate  Rate
+	Net   money.Money
+	Tax   money.Money
+	Gross money.Money
+}
+
+// Result is the tax for a whole bill
+type Result struct {
+	Lines  []LineTax
+	ByRate []RateTotal // Sorted by rate, lowest first
+	Net    money.Money
+	Tax    money.Money
+	Gross  money.Money
+}
+
+// =====================
+// CALCULATE
+// =====================
+
+// Calculate taxes every line under the jurisdiction's rules
+// In PerBill mode the per-rate totals are rounded once, so they can differ
+// by a cent from the sum of the (still shown) per-line amounts
+func (jurisdiction Jurisdiction) Calculate(lines []Line, mode Mode, currency string) (Result, error) {
+	result := Result{Net: money.Zero(currency), Tax: money.Zero(currency), Gross: money.Zero(currency)}
+	groups := map[Rate]money.Money{}
+
+	for _, line := range lines {
+		rate, err := jurisdiction.RateFor(line.Class)
+		if err != nil {
+			return Result{}, fmt.Errorf("%s: %w", line.Name, err)
+		}
+		net, tax, gross, err := jurisdiction.split(line.Amount, rate)
+		if err != nil {
+			return Result{}, fmt.Errorf("%s: %w", line.Name, err)
+		}
+		result.Lines = append(result.Lines, LineTax{Line: line, Rate: rate, Net: net, Tax: tax, Gross: gross})
+
+		if _, ok := groups[rate]; !ok {
+			groups[rate] = money.Zero(currency)
+		}
+		groups[rate] = groups[rate].Add(line.Amount)
+	}
+
+	for rate, amount := range groups {
+		total := RateTotal{Rate: rate}
+		if mode == PerBill {
+			var err error
+			if total.Net, total.Tax, total.Gross, err = jurisdiction.split(amount, rate); err != nil {
+				return Result{}, err
+			}
+		} else {
+			total.Net, total.Tax, total.Gross = money.Zero(currency), money.Zero(currency), money.Zero(currency)
+			for _, line := range result.Lines {
+				if line.Rate == rate {
+					total.Net = total.Net.Add(line.Net)
+					total.Tax = total.Tax.Add(line.Tax)
+					total.Gross = total.Gross.Add(line.Gross)
+				}
+			}
+		}
+		result.ByRate = append(result.ByRate, total)
+		result.Net = result.Net.Add(total.Net)
+		result.Tax = result.Tax.Add(total.Tax)
+		result.Gross = result.Gross.Add(total.Gross)
+	}
+
+	sort.Slice(result.ByRate, func(i, j int) bool { return result.ByRate[i].Rate < result.ByRate[j].Rate })
+	return result, nil
+}
+
+// split works out net, tax and gross for one amount
+// Tax-inclusive:  tax = gross x rate / (100% + rate)
+// Tax-exclusive:  tax = net x rate
+func (jurisdiction Jurisdiction) split(amount money.Money, rate Rate) (money.Money, money.Money, money.Money, error) {
+	if jurisdiction.PricesIncludeTax {
+		tax, err := amount.MulFraction(int64(rate), RateScale+int64(rate))
+		return amount.Sub(tax), tax, amount, err
+	}
+	tax, err := amount.MulFraction(int64(rate), RateScale)
+	return amount, tax, amount.Add(tax), err
+}
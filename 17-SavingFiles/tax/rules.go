@@ -0,0 +1,144 @@
+package tax
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// =====================
+// TAX CLASSES AND RATES
+// =====================
+
+// Tax classes used by the default menu
+// Any other name works too, as long as the jurisdiction has a rate for it
+const (
+	ClassFood    = "food"
+	ClassDrinks  = "drinks"
+	ClassAlcohol = "alcohol"
+	ClassExempt  = "exempt"
+)
+
+// Rate is a tax rate in millionths: 210000 = 21%, 88750 = 8.875%
+// Whole numbers keep the calculation exact (no float64)
+type Rate int64
+
+// RateScale is the Rate that means 100%
+const RateScale = 1000000
+
+// ParseRate reads "21", "21%" or "8.875%" (up to 4 decimals)
+func ParseRate(text string) (Rate, error) {
+	original := text
+	text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "%"))
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return 0, fmt.Errorf("%q is not a tax rate (write 0%% for no tax)", original)
+	}
+	if len(fraction) > 4 {
+		return 0, fmt.Errorf("tax rate %q has more than 4 decimals", text)
+	}
+	fraction += strings.Repeat("0", 4-len(fraction))
+
+	millionths, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || millionths < 0 {
+		return 0, fmt.Errorf("%q is not a tax rate", text)
+	}
+	return Rate(millionths), nil
+}
+
+// String prints the rate as a percentage: "21%", "8.875%"
+func (rate Rate) String() string {
+	return strconv.FormatFloat(float64(rate)/10000, 'f', -1, 64) + "%"
+}
+
+// =====================
+// JURISDICTIONS
+// =====================
+
+// Jurisdiction is one set of tax rules (a country, state or city)
+type Jurisdiction struct {
+	Name             string
+	PricesIncludeTax bool            // True when menu prices already contain the tax (EU style)
+	Rates            map[string]Rate // Tax class -> rate
+	DefaultClass     string          // Used for items without a tax class
+}
+
+// RateFor returns the rate for a tax class
+func (jurisdiction Jurisdiction) RateFor(class string) (Rate, error) {
+	if class == "" {
+		class = jurisdiction.DefaultClass
+	}
+	rate, ok := jurisdiction.Rates[class]
+	if !ok {
+		return 0, fmt.Errorf("%s has no tax rate for class %q", jurisdiction.Name, class)
+	}
+	return rate, nil
+}
+
+// configFile is the JSON layout of a tax rules file:
+//
+//	{"jurisdictions": [{"name": "NL", "prices_include_tax": true,
+//	  "default_class": "food", "rates": {"food": "9%", "alcohol": "21%"}}]}
+type configFile struct {
+	Jurisdictions []struct {
+		Name             string            `json:"name"`
+		PricesIncludeTax bool              `json:"prices_include_tax"`
+		DefaultClass     string            `json:"default_class"`
+		Rates            map[string]string `json:"rates"`
+	} `json:"jurisdictions"`
+}
+
+// Rules holds every jurisdiction loaded from config, by name
+type Rules map[string]Jurisdiction
+
+// LoadRules reads jurisdictions from a JSON config file
+func LoadRules(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config configFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	rules := Rules{}
+	for _, entry := range config.Jurisdictions {
+		jurisdiction := Jurisdiction{
+			Name:             entry.Name,
+			PricesIncludeTax: entry.PricesIncludeTax,
+			DefaultClass:     entry.DefaultClass,
+			Rates:            map[string]Rate{},
+		}
+		for class, text := range entry.Rates {
+			rate, err := ParseRate(text)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", path, entry.Name, err)
+			}
+			jurisdiction.Rates[class] = rate
+		}
+		if _, ok := jurisdiction.Rates[jurisdiction.DefaultClass]; jurisdiction.DefaultClass != "" && !ok {
+			return nil, fmt.Errorf("%s: %s: default class %q has no rate", path, entry.Name, entry.DefaultClass)
+		}
+		rules[entry.Name] = jurisdiction
+	}
+	return rules, nil
+}
+
+// Get returns one jurisdiction by name
+func (rules Rules) Get(name string) (Jurisdiction, error) {
+	jurisdiction, ok := rules[name]
+	if !ok {
+		names := make([]string, 0, len(rules))
+		for known := range rules {
+			names = append(names, known)
+		}
+		sort.Strings(names)
+		return Jurisdiction{}, fmt.Errorf("unknown jurisdiction %q (known: %s)", name, strings.Join(names, ", "))
+	}
+	return jurisdiction, nil
+}
@@ -0,0 +1,7 @@
+module 18-Interfaces
+
+go 1.25.5
+
+require 17-SavingFiles v0.0.0
+
+replace 17-SavingFiles => ../17-SavingFiles
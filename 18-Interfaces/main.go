@@ -1,7 +1,109 @@
 package main
 
-import "fmt"
+import (
+	"17-SavingFiles/bill"
+	"17-SavingFiles/menu"
+	"17-SavingFiles/money"
+	"18-Interfaces/payment"
+	"fmt"
+)
 
 func main() {
-	fmt.Println("Hello, World!")
+	// =====================
+	// A BILL TO PAY (from 17-SavingFiles)
+	// =====================
+
+	myBill := bill.NewBill("table 7", menu.Currency)
+	restaurantMenu := menu.Default()
+	myBill.AddItem(restaurantMenu, "soup", 2)
+	myBill.AddItem(restaurantMenu, "pie", 1)
+	myBill.AddItem(restaurantMenu, "wine", 2)
+	myBill.UpdateTip(money.MustParse("3.00", menu.Currency))
+	fmt.Print(myBill.Format())
+
+	ledger := payment.NewLedger(myBill.Total())
+	usd := func(amount string) money.Money { return money.MustParse(amount, menu.Currency) }
+
+	// =====================
+	// ONE INTERFACE, MANY TYPES
+	// =====================
+
+	// Voucher, Card, Cash and SplitTender are different structs,
+	// but all of them have Name, Pay and Refund - so all are PaymentMethods
+	bank := payment.NewLocalAuthorizer(usd("50.00"))
+	giftCard := payment.NewVoucher("GIFT-10", usd("10.00"))
+
+	methods := []payment.PaymentMethod{
+		giftCard,
+		payment.Card{Number: "4242 4242 4242 4242", Authorizer: bank},
+		payment.Cash{Tendered: usd("20.00")},
+	}
+	for _, method := range methods {
+		fmt.Println("Accepted method:", method.Name())
+	}
+
+	// =====================
+	// PARTIAL PAYMENTS
+	// =====================
+
+	// The voucher only has $10, so it pays part of the bill
+	pay(ledger, giftCard, usd("15.00"))
+
+	// A card with a bad number is declined (the Luhn check fails)
+	pay(ledger, payment.Card{Number: "1234 5678 9012 3456", Authorizer: bank}, usd("5.00"))
+
+	// =====================
+	// SPLIT TENDER
+	// =====================
+
+	// $8 on card + $5 cash in one go; if one part fails, the other is refunded
+	split := payment.NewSplitTender(
+		payment.Part{Method: payment.Card{Number: "4242 4242 4242 4242", Authorizer: bank}, Amount: usd("8.00")},
+		payment.Part{Method: payment.Cash{Tendered: usd("5.00")}, Amount: usd("5.00")},
+	)
+	pay(ledger, split, usd("13.00"))
+
+	// =====================
+	// CASH WITH CHANGE
+	// =====================
+
+	cashPayment, err := ledger.PayAll(payment.Cash{Tendered: usd("20.00")})
+	if err != nil {
+		fmt.Println("Error:", err)
+	} else {
+		fmt.Printf("Paid %s in cash, change: %s\n", cashPayment.Amount, cashPayment.Change)
+	}
+
+	// =====================
+	// REFUNDS
+	// =====================
+
+	// Refund $2 of the voucher payment: the voucher balance goes back up
+	if err := ledger.Refund(0, usd("2.00")); err != nil {
+		fmt.Println("Error:", err)
+	}
+	fmt.Println("Voucher balance after refund:", giftCard.Balance)
+
+	fmt.Print("\n" + ledger.Format())
+	fmt.Println("Settled:", ledger.IsSettled())
+}
+
+// pay charges one method and prints what happened
+// method can be ANY type that implements payment.PaymentMethod
+func pay(ledger *payment.Ledger, method payment.PaymentMethod, amount money.Money) {
+	result, err := ledger.Pay(method, amount)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("%s paid %s, outstanding %s\n", method.Name(), result.Amount, ledger.Outstanding())
 }
+
+// =====================
+// QUICK REFERENCE
+// =====================
+// type PaymentMethod interface { Pay(...) ... } -> a list of methods
+// Any type with those methods implements the interface automatically
+// var method PaymentMethod = Cash{...}         -> store any implementation
+// func pay(method PaymentMethod)               -> accept any implementation
+// Pointer receivers (*Voucher) mean only &Voucher / *Voucher implements it
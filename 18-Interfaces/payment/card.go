@@ -0,0 +1,144 @@
+package payment
+
+import (
+	"17-SavingFiles/money"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// =====================
+// AUTHORISER INTERFACE
+// =====================
+
+// Authorizer approves card payments (a bank in real life)
+// Card only depends on this interface, so the simulation can be swapped for a real one
+type Authorizer interface {
+	Authorize(cardNumber string, amount money.Money) (code string, err error)
+	Refund(code string, amount money.Money) error
+}
+
+// =====================
+// CARD
+// =====================
+
+// Card is a debit or credit card
+type Card struct {
+	Number     string
+	Authorizer Authorizer
+}
+
+// Name shows only the last 4 digits, never the full number
+func (card Card) Name() string {
+	digits := strings.ReplaceAll(card.Number, " ", "")
+	if len(digits) < 4 {
+		return "card"
+	}
+	return "card ****" + digits[len(digits)-4:]
+}
+
+// Pay asks the authoriser to approve the whole amount
+func (card Card) Pay(amount money.Money) (Payment, error) {
+	code, err := card.Authorizer.Authorize(card.Number, amount)
+	if err != nil {
+		return Payment{}, err
+	}
+	return Payment{Amount: amount, Change: money.Zero(amount.Currency()), Reference: code}, nil
+}
+
+// Refund sends the refund back through the authoriser
+func (card Card) Refund(payment Payment, amount money.Money) error {
+	return card.Authorizer.Refund(payment.Reference, amount)
+}
+
+// =====================
+// SIMULATED LOCAL AUTHORISER
+// =====================
+
+// LocalAuthorizer simulates a bank: it checks the card number (Luhn),
+// keeps a spending limit per card and remembers every authorisation
+type LocalAuthorizer struct {
+	DefaultLimit money.Money            // Limit for cards not in Limits
+	Limits       map[string]money.Money // Card number -> remaining limit
+	approved     map[string]money.Money // Auth code -> amount still refundable
+	cards        map[string]string      // Auth code -> card number
+	next         int
+}
+
+// NewLocalAuthorizer creates a simulated bank where every card can spend defaultLimit
+func NewLocalAuthorizer(defaultLimit money.Money) *LocalAuthorizer {
+	return &LocalAuthorizer{
+		DefaultLimit: defaultLimit,
+		Limits:       map[string]money.Money{},
+		approved:     map[string]money.Money{},
+		cards:        map[string]string{},
+	}
+}
+
+// Authorize approves the amount if the card number is valid and the limit allows it
+func (authorizer *LocalAuthorizer) Authorize(cardNumber string, amount money.Money) (string, error) {
+	number := strings.ReplaceAll(cardNumber, " ", "")
+	if !validLuhn(number) {
+		return "", fmt.Errorf("%w: invalid card number", ErrDeclined)
+	}
+
+	limit, ok := authorizer.Limits[number]
+	if !ok {
+		limit = authorizer.DefaultLimit
+	}
+	if !limit.SameCurrency(amount) {
+		return "", fmt.Errorf("%w: card does not accept %s", ErrDeclined, amount.Currency())
+	}
+	if amount.Cmp(limit) > 0 {
+		return "", fmt.Errorf("%w: insufficient funds", ErrDeclined)
+	}
+
+	authorizer.Limits[number] = limit.Sub(amount)
+	authorizer.next++
+	code := fmt.Sprintf("AUTH%06d", authorizer.next)
+	authorizer.approved[code] = amount
+	authorizer.cards[code] = number
+	return code, nil
+}
+
+// Refund returns money to the card of an earlier authorisation
+func (authorizer *LocalAuthorizer) Refund(code string, amount money.Money) error {
+	refundable, ok := authorizer.approved[code]
+	if !ok {
+		return fmt.Errorf("unknown authorisation %s", code)
+	}
+	if !amount.SameCurrency(refundable) {
+		return fmt.Errorf("%w: refund in %s", money.ErrCurrencyMismatch, refundable.Currency())
+	}
+	if amount.Cmp(refundable) > 0 {
+		return errors.New("refund is larger than the authorised amount")
+	}
+
+	number := authorizer.cards[code]
+	authorizer.approved[code] = refundable.Sub(amount)
+	authorizer.Limits[number] = authorizer.Limits[number].Add(amount)
+	return nil
+}
+
+// validLuhn checks the card number's last digit (the Luhn checksum)
+// Every second digit from the right is doubled; the sum must end in 0
+func validLuhn(number string) bool {
+	if len(number) < 12 || len(number) > 19 {
+		return false
+	}
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if digit < 0 || digit > 9 {
+			return false
+		}
+		if (len(number)-1-i)%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,41 @@
+package payment
+
+import (
+	"17-SavingFiles/money"
+	"errors"
+)
+
+// =====================
+// CASH
+// =====================
+
+// Cash is money handed over at the table
+type Cash struct {
+	Tendered money.Money // What the customer gives
+}
+
+// Name describes the method
+func (cash Cash) Name() string {
+	return "cash"
+}
+
+// Pay takes the amount and gives change for the rest of the tendered cash
+// Not enough cash is a partial payment of everything tendered
+func (cash Cash) Pay(amount money.Money) (Payment, error) {
+	if cash.Tendered.IsZero() || cash.Tendered.IsNegative() {
+		return Payment{}, errors.New("no cash tendered")
+	}
+	if !cash.Tendered.SameCurrency(amount) {
+		return Payment{}, money.ErrCurrencyMismatch
+	}
+
+	if cash.Tendered.Cmp(amount) < 0 {
+		return Payment{Amount: cash.Tendered, Change: money.Zero(amount.Currency())}, nil
+	}
+	return Payment{Amount: amount, Change: cash.Tendered.Sub(amount)}, nil
+}
+
+// Refund hands cash back over the counter, up to what was paid
+func (cash Cash) Refund(payment Payment, amount money.Money) error {
+	return checkRefund(payment, amount)
+}
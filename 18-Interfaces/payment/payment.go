@@ -0,0 +1,176 @@
+package payment
+
+import (
+	"17-SavingFiles/money"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// =====================
+// THE INTERFACE
+// =====================
+
+// PaymentMethod is anything a bill can be paid with
+// An interface only lists methods: any type that has them "is" a PaymentMethod
+// (Go has no "implements" keyword - it is automatic)
+type PaymentMethod interface {
+	// Name describes the method on the receipt (e.g., "card ****4242")
+	Name() string
+
+	// Pay takes up to amount and returns what was actually taken
+	// A method may take less (e.g., a voucher with a small balance): that is a partial payment
+	Pay(amount money.Money) (Payment, error)
+
+	// Refund gives back (part of) an earlier payment made with this method
+	Refund(payment Payment, amount money.Money) error
+}
+
+// =====================
+// PAYMENT RECORD
+// =====================
+
+// Payment records one tender
+type Payment struct {
+	Method    string      // PaymentMethod.Name() at the time of paying
+	Amount    money.Money // Put towards the bill
+	Change    money.Money // Cash given back (cash only)
+	Reference string      // Authorisation code, voucher code...
+	Refunded  money.Money // How much was refunded later
+	At        time.Time
+
+	method PaymentMethod // Kept so Refund can call the right method
+	parts  []Payment     // What each part of a split tender paid
+}
+
+// refundable is what is left of the payment to give back
+func (payment Payment) refundable() money.Money {
+	return payment.Amount.Sub(payment.Refunded)
+}
+
+// checkRefund refuses a refund that is empty, in another currency
+// or larger than what is left of the payment
+func checkRefund(payment Payment, amount money.Money) error {
+	if !amount.SameCurrency(payment.Amount) {
+		return fmt.Errorf("%w: refund in %s", money.ErrCurrencyMismatch, payment.Amount.Currency())
+	}
+	if amount.IsZero() || amount.IsNegative() {
+		return errors.New("refund must be above zero")
+	}
+	if amount.Cmp(payment.refundable()) > 0 {
+		return fmt.Errorf("refund is larger than the %s left of the payment", payment.refundable())
+	}
+	return nil
+}
+
+// ErrDeclined is returned when a method cannot pay at all
+var ErrDeclined = errors.New("payment declined")
+
+// =====================
+// LEDGER
+// =====================
+
+// Ledger tracks the payments made against one bill
+type Ledger struct {
+	Due      money.Money
+	Payments []Payment
+	clock    func() time.Time
+}
+
+// NewLedger creates a ledger for a bill total
+func NewLedger(due money.Money) *Ledger {
+	return &Ledger{Due: due, clock: time.Now}
+}
+
+// Paid is everything received so far, minus refunds
+func (ledger *Ledger) Paid() money.Money {
+	paid := money.Zero(ledger.Due.Currency())
+	for _, payment := range ledger.Payments {
+		paid = paid.Add(payment.Amount).Sub(payment.Refunded)
+	}
+	return paid
+}
+
+// Outstanding is what is still left to pay
+func (ledger *Ledger) Outstanding() money.Money {
+	return ledger.Due.Sub(ledger.Paid())
+}
+
+// IsSettled reports whether the bill is fully paid
+func (ledger *Ledger) IsSettled() bool {
+	return ledger.Outstanding().IsZero()
+}
+
+// Pay charges a method for (at most) amount, capped at what is outstanding
+// The method can be any type that implements PaymentMethod
+func (ledger *Ledger) Pay(method PaymentMethod, amount money.Money) (Payment, error) {
+	outstanding := ledger.Outstanding()
+	if !amount.SameCurrency(outstanding) {
+		return Payment{}, fmt.Errorf("%w: pay in %s", money.ErrCurrencyMismatch, outstanding.Currency())
+	}
+	if outstanding.IsZero() || outstanding.IsNegative() {
+		return Payment{}, errors.New("the bill is already paid")
+	}
+	if amount.IsZero() || amount.IsNegative() {
+		return Payment{}, errors.New("amount must be above zero")
+	}
+	if amount.Cmp(outstanding) > 0 {
+		amount = outstanding
+	}
+
+	payment, err := method.Pay(amount)
+	if err != nil {
+		return Payment{}, fmt.Errorf("%s: %w", method.Name(), err)
+	}
+	payment.Method = method.Name()
+	payment.At = ledger.clock()
+	payment.method = method
+	if payment.Refunded.Currency() == "" {
+		payment.Refunded = money.Zero(amount.Currency())
+	}
+	ledger.Payments = append(ledger.Payments, payment)
+	return payment, nil
+}
+
+// PayAll charges the method for everything outstanding
+func (ledger *Ledger) PayAll(method PaymentMethod) (Payment, error) {
+	return ledger.Pay(method, ledger.Outstanding())
+}
+
+// Refund gives back amount of payment number index (0 = first payment)
+func (ledger *Ledger) Refund(index int, amount money.Money) error {
+	if index < 0 || index >= len(ledger.Payments) {
+		return fmt.Errorf("no payment number %d", index+1)
+	}
+	payment := &ledger.Payments[index]
+
+	if err := checkRefund(*payment, amount); err != nil {
+		return err
+	}
+	if err := payment.method.Refund(*payment, amount); err != nil {
+		return fmt.Errorf("%s: %w", payment.Method, err)
+	}
+	payment.Refunded = payment.Refunded.Add(amount)
+	return nil
+}
+
+// Format lists every payment and what is still outstanding
+func (ledger *Ledger) Format() string {
+	formatted := "Payments:\n"
+	for i, payment := range ledger.Payments {
+		formatted += fmt.Sprintf("  %d. %-22s %s", i+1, payment.Method, payment.Amount)
+		if !payment.Change.IsZero() {
+			formatted += fmt.Sprintf(" (change %s)", payment.Change)
+		}
+		if payment.Reference != "" {
+			formatted += " ref " + payment.Reference
+		}
+		if !payment.Refunded.IsZero() {
+			formatted += fmt.Sprintf(", refunded %s", payment.Refunded)
+		}
+		formatted += "\n"
+	}
+	formatted += fmt.Sprintf("  %-25s %s\n", "paid:", ledger.Paid())
+	formatted += fmt.Sprintf("  %-25s %s\n", "outstanding:", ledger.Outstanding())
+	return formatted
+}
@@ -0,0 +1,126 @@
+package payment
+
+import (
+	"17-SavingFiles/money"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// =====================
+// SPLIT TENDER
+// =====================
+
+// Part is one method and the amount it should cover
+type Part struct {
+	Method PaymentMethod
+	Amount money.Money
+}
+
+// SplitTender pays with several methods at once (e.g., $10 voucher + rest on card)
+// It is itself a PaymentMethod, so a Ledger treats it like any other method
+// What each part paid travels with the returned Payment, so one SplitTender
+// can pay several bills and each payment can still be refunded on its own
+type SplitTender struct {
+	Parts []Part
+}
+
+// NewSplitTender groups several methods into one payment
+func NewSplitTender(parts ...Part) *SplitTender {
+	return &SplitTender{Parts: parts}
+}
+
+// Name lists the methods inside
+func (split *SplitTender) Name() string {
+	names := make([]string, len(split.Parts))
+	for i, part := range split.Parts {
+		names[i] = part.Method.Name()
+	}
+	return "split (" + strings.Join(names, " + ") + ")"
+}
+
+// Pay charges each part in order
+// If any part fails, the parts already charged are refunded and nothing is paid
+func (split *SplitTender) Pay(amount money.Money) (Payment, error) {
+	total := money.Zero(amount.Currency())
+	for _, part := range split.Parts {
+		total = total.Add(part.Amount)
+	}
+	if total.Cmp(amount) > 0 {
+		return Payment{}, fmt.Errorf("parts add up to %s, more than %s", total, amount)
+	}
+
+	taken := []Payment{}
+	paid := money.Zero(amount.Currency())
+	change := money.Zero(amount.Currency())
+	references := []string{}
+	for _, part := range split.Parts {
+		payment, err := part.Method.Pay(part.Amount)
+		if err == nil && payment.Amount.Cmp(part.Amount) < 0 {
+			err = errors.New("could only pay " + payment.Amount.String())
+			if refundErr := part.Method.Refund(payment, payment.Amount); refundErr != nil {
+				err = errors.Join(err, fmt.Errorf("refunding %s: %w", payment.Amount, refundErr))
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("%s: %w", part.Method.Name(), err)
+			return Payment{}, errors.Join(err, rollback(taken))
+		}
+		payment.Method = part.Method.Name()
+		payment.method = part.Method
+		taken = append(taken, payment)
+		paid = paid.Add(payment.Amount)
+		change = change.Add(payment.Change)
+		if payment.Reference != "" {
+			references = append(references, payment.Reference)
+		}
+	}
+	return Payment{Amount: paid, Change: change, Reference: strings.Join(references, ","), parts: taken}, nil
+}
+
+// Refund gives money back to the parts of payment, last part first
+// The parts share their backing array with the ledger's copy of the payment,
+// so what each part got back is remembered for the next refund
+// If a part fails, the error says how much the earlier parts already gave back
+func (split *SplitTender) Refund(payment Payment, amount money.Money) error {
+	if len(payment.parts) == 0 {
+		return errors.New("payment was not made with a split tender")
+	}
+	if err := checkRefund(payment, amount); err != nil {
+		return err
+	}
+
+	refunded := money.Zero(amount.Currency())
+	for i := len(payment.parts) - 1; i >= 0 && !amount.IsZero(); i-- {
+		part := &payment.parts[i]
+		refundable := part.refundable()
+		if refundable.IsZero() {
+			continue
+		}
+		share := amount
+		if share.Cmp(refundable) > 0 {
+			share = refundable
+		}
+		if err := part.method.Refund(*part, share); err != nil {
+			if refunded.IsZero() {
+				return fmt.Errorf("%s: %w", part.Method, err)
+			}
+			return fmt.Errorf("%s: %w (%s already refunded)", part.Method, err, refunded)
+		}
+		part.Refunded = part.Refunded.Add(share)
+		refunded = refunded.Add(share)
+		amount = amount.Sub(share)
+	}
+	return nil
+}
+
+// rollback refunds every part already charged and reports every refund that failed
+func rollback(taken []Payment) error {
+	var errs []error
+	for _, payment := range taken {
+		if err := payment.method.Refund(payment, payment.Amount); err != nil {
+			errs = append(errs, fmt.Errorf("refunding %s: %w", payment.Method, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,56 @@
+package payment
+
+import (
+	"17-SavingFiles/money"
+	"fmt"
+)
+
+// =====================
+// VOUCHER
+// =====================
+
+// Voucher is a gift card with a balance
+// Pointer receivers (*Voucher) because paying changes the balance
+type Voucher struct {
+	Code    string
+	Balance money.Money
+}
+
+// NewVoucher creates a voucher with a starting balance
+func NewVoucher(code string, balance money.Money) *Voucher {
+	return &Voucher{Code: code, Balance: balance}
+}
+
+// Name describes the method
+func (voucher *Voucher) Name() string {
+	return "voucher " + voucher.Code
+}
+
+// Pay uses the balance, up to amount (a small balance gives a partial payment)
+func (voucher *Voucher) Pay(amount money.Money) (Payment, error) {
+	if !voucher.Balance.SameCurrency(amount) {
+		return Payment{}, money.ErrCurrencyMismatch
+	}
+	if voucher.Balance.IsZero() || voucher.Balance.IsNegative() {
+		return Payment{}, fmt.Errorf("%w: voucher %s is empty", ErrDeclined, voucher.Code)
+	}
+
+	taken := amount
+	if voucher.Balance.Cmp(amount) < 0 {
+		taken = voucher.Balance
+	}
+	voucher.Balance = voucher.Balance.Sub(taken)
+	return Payment{Amount: taken, Change: money.Zero(amount.Currency()), Reference: voucher.Code}, nil
+}
+
+// Refund puts the amount back on the voucher the payment was made with
+func (voucher *Voucher) Refund(payment Payment, amount money.Money) error {
+	if payment.Reference != voucher.Code {
+		return fmt.Errorf("payment %s was not made with voucher %s", payment.Reference, voucher.Code)
+	}
+	if err := checkRefund(payment, amount); err != nil {
+		return err
+	}
+	voucher.Balance = voucher.Balance.Add(amount)
+	return nil
+}